@@ -0,0 +1,58 @@
+package provider
+
+import (
+	"math/rand"
+	"time"
+)
+
+const (
+	retryJitterFull  = "full"
+	retryJitterEqual = "equal"
+	retryJitterNone  = "none"
+
+	defaultRetryBaseDelay = 100 * time.Millisecond
+	defaultRetryMaxDelay  = 5 * time.Second
+)
+
+// retryBackoff is the exponential backoff curve shared by every retry: DNS
+// failures, 412s from performance standbys and merge-retry CAS conflicts.
+type retryBackoff struct {
+	// Base is the wait before the first retry; it doubles with every further
+	// attempt up to Max.
+	Base time.Duration
+	Max  time.Duration
+
+	// Jitter randomizes each wait so racing clients spread out:
+	// retryJitterFull waits anywhere up to the computed delay,
+	// retryJitterEqual at least half of it and retryJitterNone exactly it.
+	Jitter string
+}
+
+var defaultRetryBackoff = retryBackoff{
+	Base:   defaultRetryBaseDelay,
+	Max:    defaultRetryMaxDelay,
+	Jitter: retryJitterFull,
+}
+
+// delay returns the wait before retry number attempt, counting from 0.
+func (b retryBackoff) delay(attempt int) time.Duration {
+	d := b.Base
+	for i := 0; i < attempt && d < b.Max; i++ {
+		d *= 2
+	}
+	if b.Max > 0 && d > b.Max {
+		d = b.Max
+	}
+	if d <= 0 {
+		return 0
+	}
+
+	switch b.Jitter {
+	case retryJitterNone:
+		return d
+	case retryJitterEqual:
+		return d/2 + time.Duration(rand.Int63n(int64(d/2)+1))
+	default:
+		return time.Duration(rand.Int63n(int64(d) + 1))
+	}
+}
@@ -0,0 +1,38 @@
+package provider
+
+import (
+	"encoding/json"
+	"os"
+	"time"
+)
+
+// backupContent is the content of a backup_file: the managed keys of one
+// secret as last written, in plain text.
+type backupContent struct {
+	Mount     string            `json:"mount"`
+	Path      string            `json:"path"`
+	Version   int64             `json:"version"`
+	WrittenAt time.Time         `json:"written_at"`
+	Keys      map[string]string `json:"keys"`
+}
+
+// writeBackupFile replaces file with content, readable only by its owner.
+func writeBackupFile(file string, content backupContent) error {
+	raw, err := json.MarshalIndent(content, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	f, err := os.OpenFile(file, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0o600)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	// OpenFile keeps the mode of an existing file, so tighten it explicitly.
+	if err := f.Chmod(0o600); err != nil {
+		return err
+	}
+	_, err = f.Write(raw)
+	return err
+}
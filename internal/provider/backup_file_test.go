@@ -0,0 +1,67 @@
+package provider
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+func TestBackupKeys(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "backup.json")
+	model := KvKeysResourceModel{
+		Mount:                types.StringValue("app"),
+		Path:                 types.StringValue("svc"),
+		BackupFile:           types.StringValue(file),
+		AllowPlaintextBackup: types.BoolValue(true),
+	}
+	keys := map[string]string{"API_KEY": "abc"}
+
+	var diags diag.Diagnostics
+	backupKeys(model, keys, 4, &diags)
+	if diags.HasError() || diags.WarningsCount() > 0 {
+		t.Fatalf("unexpected diagnostics: %v", diags)
+	}
+
+	info, err := os.Stat(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if mode := info.Mode().Perm(); mode != 0o600 {
+		t.Errorf("mode = %o, want 600", mode)
+	}
+
+	raw, err := os.ReadFile(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var content backupContent
+	if err := json.Unmarshal(raw, &content); err != nil {
+		t.Fatal(err)
+	}
+	if content.Mount != "app" || content.Path != "svc" || content.Version != 4 {
+		t.Errorf("unexpected header %+v", content)
+	}
+	if !reflect.DeepEqual(content.Keys, keys) {
+		t.Errorf("keys = %v, want %v", content.Keys, keys)
+	}
+}
+
+func TestBackupKeysRequiresAcknowledgement(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "backup.json")
+	model := KvKeysResourceModel{
+		Mount:      types.StringValue("app"),
+		Path:       types.StringValue("svc"),
+		BackupFile: types.StringValue(file),
+	}
+
+	var diags diag.Diagnostics
+	backupKeys(model, map[string]string{"API_KEY": "abc"}, 1, &diags)
+	if _, err := os.Stat(file); !os.IsNotExist(err) {
+		t.Errorf("expected no backup without allow_plaintext_backup, got %v", err)
+	}
+}
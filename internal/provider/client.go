@@ -0,0 +1,1866 @@
+package provider
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+type VaultClient struct {
+	Address    string
+	Token      string
+	HTTPClient *http.Client
+
+	// ResponseDataPath is a dotted JSON path to the secret data in read
+	// responses, for gateways that reshape them. Empty means the KV shape
+	// ("data.data" on v2, "data" on v1).
+	ResponseDataPath string
+
+	// MaxRetries is how many times a read is retried on transient Vault
+	// responses (412 from a performance standby).
+	MaxRetries int
+
+	// DNSRetries is how many times a request is retried, with backoff, when
+	// the Vault host name fails to resolve.
+	DNSRetries int
+
+	// Backoff is the wait curve between retries of any kind.
+	Backoff retryBackoff
+
+	// FlattenUnder makes secret reads and writes address the fields of the
+	// object stored under this top-level key, keeping its siblings. Set per
+	// resource through withFlattenUnder.
+	FlattenUnder string
+
+	// ValueTypes maps key names to the JSON type (valueTypeNumber or
+	// valueTypeBoolean) their string values are written as. Set per resource
+	// through withValueTypes.
+	ValueTypes map[string]string
+
+	// Headers are added to every request. Reserved headers are never
+	// overridden by them.
+	Headers map[string]string
+
+	// Namespace is the Vault Enterprise namespace sent as X-Vault-Namespace
+	// with every request, taking precedence over Headers. Empty sends none.
+	// Set per resource through withNamespace.
+	Namespace string
+
+	// NamespaceMode selects how Namespace is sent: namespaceModeHeader (the
+	// default) or namespaceModePath, as a URL prefix for gateways.
+	NamespaceMode string
+
+	// TokenExpiry is when the login token expires, from the lease Vault
+	// granted at login. Zero when the token has no TTL.
+	TokenExpiry time.Time
+
+	// TokenAccessor, TokenPolicies and TokenRenewable describe the login
+	// token, from the login response. Empty when a cached or static token is
+	// used.
+	TokenAccessor  string
+	TokenPolicies  []string
+	TokenRenewable bool
+
+	// ReadCache enables deduplication of secret reads within a single run.
+	ReadCache bool
+
+	// TokenHeaderStyle selects the header carrying the token:
+	// tokenHeaderVault (the default) or tokenHeaderBearer.
+	TokenHeaderStyle string
+
+	// TokenInQuery sends the token as a query parameter instead of the
+	// X-Vault-Token header, for gateways that strip the header.
+	TokenInQuery bool
+
+	// RequestJSONIndent indents JSON request bodies instead of sending them
+	// compact, and RequestTrailingNewline ends them with a newline, for
+	// gateways that are strict about formatting.
+	RequestJSONIndent      bool
+	RequestTrailingNewline bool
+
+	// ConditionalReads skips re-reading secret data when the path's metadata
+	// reports the same version as the last full read.
+	ConditionalReads bool
+
+	// ReadOnly makes every resource Create, Update and Delete fail before
+	// touching Vault.
+	ReadOnly bool
+
+	// DryRun makes writeSecret log the intended write instead of sending it.
+	DryRun bool
+
+	// ContentAddressedWrites records a content hash in custom metadata and
+	// skips writes whose content matches the hash of the current version.
+	ContentAddressedWrites bool
+
+	// AllowValueCommands lets resources run their value_command and
+	// value_read_command programs.
+	AllowValueCommands bool
+
+	// DefaultCustomMetadata holds custom_metadata entries added after every
+	// KV v2 write to secrets that do not have them yet.
+	DefaultCustomMetadata map[string]string
+
+	// CustomMetadata holds custom_metadata entries set after every KV v2
+	// write, overriding defaults and live values. Set per resource through
+	// withCustomMetadata.
+	CustomMetadata map[string]string
+
+	// VerifyWrite reads every write back and fails if the stored data differs
+	// from what was sent.
+	VerifyWrite bool
+
+	// ControlGroupWait is how long a request held by a Vault Enterprise
+	// Control Group waits for approval. Zero fails it right away.
+	ControlGroupWait time.Duration
+
+	// EventualConsistency stops reads from sending the last seen
+	// X-Vault-Index, so standbys answer them even when behind this run's
+	// writes. Set per data source through withEventualConsistency.
+	EventualConsistency bool
+
+	// MaskLogValues scrubs managed values from all tflog output.
+	MaskLogValues bool
+
+	// OperationLogLevel is the level of the per-operation messages resources
+	// log: operationLogInfo (the default when empty), operationLogDebug or
+	// operationLogOff.
+	OperationLogLevel string
+
+	// MaxRequestBytes rejects writes whose encoded payload exceeds it before
+	// they are sent. Zero disables the check.
+	MaxRequestBytes int
+
+	// MaxSecretBytes rejects writes whose encoded secret data exceeds it
+	// before they are sent, for storage backends with a max entry size.
+	// Zero disables the check.
+	MaxSecretBytes int
+
+	// KVVersion selects the KV engine API (1 or 2) used for secret reads and
+	// writes. Zero means 2. Set per resource through withKVVersion.
+	KVVersion int
+
+	cache *clientCache
+}
+
+// clientCache holds the state shared by a client and all of its scoped
+// copies.
+type clientCache struct {
+	mu             sync.Mutex
+	mounts         map[string]mountInfo
+	secretReads    map[string]*cachedRead
+	secretVersions map[string]versionedSecret
+
+	// leases holds the lease information of the last read of each path that
+	// returned any.
+	leases map[string]secretLease
+
+	// lastIndex is the latest X-Vault-Index Vault returned, sent with reads
+	// so a standby that has not caught up answers 412 instead of stale data.
+	lastIndex string
+}
+
+// withKVVersion returns a copy of the client that talks to a KV engine of the
+// given version, sharing caches with c.
+func (c *VaultClient) withKVVersion(version int) *VaultClient {
+	scoped := *c
+	scoped.KVVersion = version
+	return &scoped
+}
+
+// withEventualConsistency returns a copy of the client whose reads do not
+// require the serving node to have caught up with this run's writes,
+// sharing caches with c.
+func (c *VaultClient) withEventualConsistency() *VaultClient {
+	scoped := *c
+	scoped.EventualConsistency = true
+	return &scoped
+}
+
+// withHeaders returns a copy of the client that sends headers on top of its
+// own, sharing caches with c.
+func (c *VaultClient) withHeaders(headers map[string]string) *VaultClient {
+	scoped := *c
+	scoped.Headers = mergeKeys(c.Headers, headers)
+	return &scoped
+}
+
+// withCustomMetadata returns a copy of the client that sets custom on every
+// secret it writes, sharing caches with c.
+func (c *VaultClient) withCustomMetadata(custom map[string]string) *VaultClient {
+	scoped := *c
+	scoped.CustomMetadata = custom
+	return &scoped
+}
+
+// withNamespace returns a copy of the client whose requests target the
+// given namespace, sharing caches with c.
+func (c *VaultClient) withNamespace(namespace string) *VaultClient {
+	scoped := *c
+	scoped.Namespace = namespace
+	return &scoped
+}
+
+// withFlattenUnder returns a copy of the client whose secret reads and
+// writes address the fields of the object stored under key instead of the
+// top-level secret data, sharing caches with c.
+func (c *VaultClient) withFlattenUnder(key string) *VaultClient {
+	scoped := *c
+	scoped.FlattenUnder = key
+	return &scoped
+}
+
+// withValueTypes returns a copy of the client that writes the keys in types
+// as the given JSON types, sharing caches with c.
+func (c *VaultClient) withValueTypes(types map[string]string) *VaultClient {
+	scoped := *c
+	scoped.ValueTypes = types
+	return &scoped
+}
+
+// reservedHeaders are set by the client itself and cannot be configured.
+var reservedHeaders = []string{"X-Vault-Token", "X-Vault-Namespace", "Authorization", "Content-Type"}
+
+// isReservedHeader reports whether name is one of reservedHeaders, in any
+// letter case.
+func isReservedHeader(name string) bool {
+	for _, reserved := range reservedHeaders {
+		if http.CanonicalHeaderKey(name) == reserved {
+			return true
+		}
+	}
+	return false
+}
+
+func (c *VaultClient) kvV1() bool {
+	return c.KVVersion == 1
+}
+
+// minMaskedValueLength skips masking values so short that scrubbing them
+// would garble unrelated log output (e.g. "1" or "on").
+const minMaskedValueLength = 4
+
+// maskValues returns a context whose tflog messages and fields have every
+// value in the given maps replaced, as a safeguard against a secret ever
+// reaching a log line.
+func (c *VaultClient) maskValues(ctx context.Context, maps ...map[string]string) context.Context {
+	if !c.MaskLogValues {
+		return ctx
+	}
+
+	var values []string
+	for _, m := range maps {
+		for _, v := range m {
+			if len(v) >= minMaskedValueLength {
+				values = append(values, v)
+			}
+		}
+	}
+	if len(values) == 0 {
+		return ctx
+	}
+
+	ctx = tflog.MaskMessageStrings(ctx, values...)
+	return tflog.MaskAllFieldValuesStrings(ctx, values...)
+}
+
+const (
+	operationLogInfo  = "info"
+	operationLogDebug = "debug"
+	operationLogOff   = "off"
+)
+
+// logOperation logs a resource's per-operation message at OperationLogLevel.
+// Fields carry key names only; values never reach it.
+func (c *VaultClient) logOperation(ctx context.Context, msg string, fields map[string]interface{}) {
+	switch c.OperationLogLevel {
+	case operationLogOff:
+	case operationLogDebug:
+		tflog.Debug(ctx, msg, fields)
+	default:
+		tflog.Info(ctx, msg, fields)
+	}
+}
+
+type versionedSecret struct {
+	data    map[string]string
+	version int64
+}
+
+// secretMetadata is the subset of a KV v2 metadata response the provider uses.
+type secretMetadata struct {
+	CurrentVersion int64                            `json:"current_version"`
+	OldestVersion  int64                            `json:"oldest_version"`
+	MaxVersions    int64                            `json:"max_versions"`
+	CustomMetadata map[string]string                `json:"custom_metadata"`
+	CreatedTime    string                           `json:"created_time"`
+	UpdatedTime    string                           `json:"updated_time"`
+	Versions       map[string]secretVersionMetadata `json:"versions"`
+}
+
+type secretVersionMetadata struct {
+	DeletionTime string `json:"deletion_time"`
+	Destroyed    bool   `json:"destroyed"`
+}
+
+// latestSoftDeleted reports whether the current version has been deleted
+// but not destroyed, so it can still be undeleted. A deletion scheduled in
+// the future (delete_version_after) does not count yet.
+func (m *secretMetadata) latestSoftDeleted() bool {
+	latest, ok := m.Versions[strconv.FormatInt(m.CurrentVersion, 10)]
+	if !ok || latest.Destroyed || latest.DeletionTime == "" {
+		return false
+	}
+	deleted, err := time.Parse(time.RFC3339Nano, latest.DeletionTime)
+	return err == nil && !deleted.After(time.Now())
+}
+
+// cachedRead is a secret read shared by every caller asking for the same
+// path while the cache is enabled. done is closed once data/err are set.
+type cachedRead struct {
+	done    chan struct{}
+	data    map[string]string
+	version int64
+	err     error
+}
+
+// newRequest builds a request for the given Vault API path (relative to /v1)
+// carrying the client token.
+func (c *VaultClient) newRequest(ctx context.Context, method, apiPath string, body io.Reader) (*http.Request, error) {
+	apiURL := fmt.Sprintf("%s/v1/%s", namespaceBase(c.Address, c.Namespace, c.NamespaceMode), apiPath)
+	req, err := http.NewRequestWithContext(ctx, method, apiURL, body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	c.logTokenTTL(ctx)
+
+	for name, value := range c.Headers {
+		if !isReservedHeader(name) {
+			req.Header.Set(name, value)
+		}
+	}
+	setNamespaceHeader(req, c.Namespace, c.NamespaceMode)
+
+	if method == "GET" && !c.EventualConsistency {
+		c.cache.mu.Lock()
+		index := c.cache.lastIndex
+		c.cache.mu.Unlock()
+		if index != "" {
+			req.Header.Set("X-Vault-Index", index)
+		}
+	}
+
+	if c.TokenInQuery {
+		query := req.URL.Query()
+		query.Set("token", c.Token)
+		req.URL.RawQuery = query.Encode()
+	} else {
+		setTokenHeader(req, c.TokenHeaderStyle, c.Token)
+	}
+
+	return req, nil
+}
+
+const (
+	tokenHeaderVault  = "x-vault-token"
+	tokenHeaderBearer = "bearer"
+)
+
+// setTokenHeader sends token in the X-Vault-Token header, or as an
+// "Authorization: Bearer" header when style is tokenHeaderBearer.
+func setTokenHeader(req *http.Request, style, token string) {
+	if style == tokenHeaderBearer {
+		req.Header.Set("Authorization", "Bearer "+token)
+		return
+	}
+	req.Header.Set("X-Vault-Token", token)
+}
+
+const (
+	namespaceModeHeader = "header"
+	namespaceModePath   = "path"
+)
+
+// namespaceBase returns the URL the API of namespace lives under: address
+// itself, or address/namespace when mode is namespaceModePath.
+func namespaceBase(address, namespace, mode string) string {
+	if namespace == "" || mode != namespaceModePath {
+		return address
+	}
+	return address + "/" + namespace
+}
+
+// setNamespaceHeader sends namespace as X-Vault-Namespace unless mode puts it
+// in the URL.
+func setNamespaceHeader(req *http.Request, namespace, mode string) {
+	if namespace != "" && mode != namespaceModePath {
+		req.Header.Set("X-Vault-Namespace", namespace)
+	}
+}
+
+// logTokenTTL emits the remaining token lifetime so long runs can be
+// monitored for expiry. Nothing is logged for tokens without a TTL.
+func (c *VaultClient) logTokenTTL(ctx context.Context) {
+	if c.TokenExpiry.IsZero() {
+		return
+	}
+	tflog.Debug(ctx, "Vault token TTL remaining", map[string]interface{}{
+		"token_ttl_remaining_seconds": int64(time.Until(c.TokenExpiry).Seconds()),
+	})
+}
+
+// do sends a request built by newRequest. Transport errors never include the
+// query string, so a token sent via token_in_query cannot leak into diagnostics.
+// Any Warning headers on the response (e.g. API deprecations) are logged.
+func (c *VaultClient) do(req *http.Request) (*http.Response, error) {
+	resp, err := sendWithDNSRetries(c.HTTPClient, req, c.DNSRetries, c.Backoff)
+	if err != nil {
+		return nil, fmt.Errorf("failed to send request: %w", err)
+	}
+
+	if index := resp.Header.Get("X-Vault-Index"); index != "" && req.Method != "GET" {
+		c.cache.mu.Lock()
+		c.cache.lastIndex = index
+		c.cache.mu.Unlock()
+	}
+
+	for _, warning := range resp.Header.Values("Warning") {
+		tflog.Warn(req.Context(), "Vault returned a warning header", map[string]interface{}{
+			"method":  req.Method,
+			"path":    req.URL.Path,
+			"warning": warning,
+		})
+	}
+
+	return resp, nil
+}
+
+// sendWithDNSRetries sends req, retrying up to retries times with backoff
+// while the Vault host name fails to resolve. URLs in the returned error are
+// redacted.
+func sendWithDNSRetries(httpClient *http.Client, req *http.Request, retries int, backoff retryBackoff) (*http.Response, error) {
+	resp, err := httpClient.Do(req)
+	for attempt := 0; err != nil && isDNSError(err) && attempt < retries; attempt++ {
+		redactURLError(err)
+		delay := backoff.delay(attempt)
+		tflog.Warn(req.Context(), "Could not resolve the Vault host, retrying", map[string]interface{}{
+			"attempt": attempt + 1,
+			"delay":   delay.String(),
+			"error":   err.Error(),
+		})
+		select {
+		case <-req.Context().Done():
+			return nil, req.Context().Err()
+		case <-time.After(delay):
+		}
+		if req.GetBody != nil {
+			body, bodyErr := req.GetBody()
+			if bodyErr != nil {
+				return nil, fmt.Errorf("failed to rewind request body: %w", bodyErr)
+			}
+			req.Body = body
+		}
+		resp, err = httpClient.Do(req)
+	}
+	if err != nil {
+		redactURLError(err)
+		return nil, err
+	}
+	return resp, nil
+}
+
+// redactURLError drops the query string from the URL of a transport error,
+// where token_in_query puts the token.
+func redactURLError(err error) {
+	var urlErr *url.Error
+	if !errors.As(err, &urlErr) {
+		return
+	}
+	redacted, parseErr := url.Parse(urlErr.URL)
+	if parseErr != nil {
+		urlErr.URL = ""
+		return
+	}
+	redacted.RawQuery = ""
+	urlErr.URL = redacted.String()
+}
+
+// errSchemeDowngrade is returned when Vault redirects an https request to
+// an http URL and downgrades are not allowed.
+var errSchemeDowngrade = errors.New("refusing redirect from https to http")
+
+// redirectPolicy returns an http.Client CheckRedirect function that rejects
+// https to http redirects unless allowDowngrade is set. Such redirects
+// usually come from a Vault api_addr or redirect_addr configured with http.
+func redirectPolicy(allowDowngrade bool) func(*http.Request, []*http.Request) error {
+	return func(req *http.Request, via []*http.Request) error {
+		if len(via) >= 10 {
+			return errors.New("stopped after 10 redirects")
+		}
+		if !allowDowngrade && via[len(via)-1].URL.Scheme == "https" && req.URL.Scheme == "http" {
+			return fmt.Errorf("%w (to %s): check the api_addr/redirect_addr of the Vault server, "+
+				"or set allow_scheme_downgrade on the provider", errSchemeDowngrade, req.URL.Host)
+		}
+		return nil
+	}
+}
+
+// isDNSError reports whether err is a failure to resolve a host name, e.g.
+// "dial tcp: lookup vault.example.com: no such host".
+func isDNSError(err error) bool {
+	var dnsErr *net.DNSError
+	return errors.As(err, &dnsErr)
+}
+
+// mountAccessor resolves the accessor of the given mount via sys/mounts.
+func (c *VaultClient) mountAccessor(ctx context.Context, mount string) (string, error) {
+	info, err := c.mountDetails(ctx, mount)
+	if err != nil {
+		return "", err
+	}
+	if info.Accessor == "" {
+		return "", fmt.Errorf("vault returned no accessor for mount %s", mount)
+	}
+	return info.Accessor, nil
+}
+
+// mountKVVersion returns the KV engine version (1 or 2) of the given mount
+// from its options in sys/mounts.
+func (c *VaultClient) mountKVVersion(ctx context.Context, mount string) (int, error) {
+	info, err := c.mountDetails(ctx, mount)
+	if err != nil {
+		return 0, err
+	}
+	if info.Type != "kv" && info.Type != "generic" {
+		return 0, fmt.Errorf("mount %s is a %q engine, not KV", mount, info.Type)
+	}
+	if info.Version == "2" {
+		return 2, nil
+	}
+	return 1, nil
+}
+
+// mountDetails reads the given mount from sys/mounts. Results are cached for
+// the lifetime of the client since they only change when a mount is
+// re-enabled.
+func (c *VaultClient) mountDetails(ctx context.Context, mount string) (mountInfo, error) {
+	c.cache.mu.Lock()
+	info, ok := c.cache.mounts[c.cacheKey(mount)]
+	c.cache.mu.Unlock()
+	if ok {
+		return info, nil
+	}
+
+	req, err := c.newRequest(ctx, "GET", "sys/mounts/"+mount, nil)
+	if err != nil {
+		return mountInfo{}, err
+	}
+	req.Header.Set("X-Vault-Request", "true")
+
+	resp, err := c.do(req)
+	if err != nil {
+		return mountInfo{}, err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return mountInfo{}, fmt.Errorf("failed to read response: %w", err)
+	}
+
+	if resp.StatusCode == http.StatusForbidden {
+		return mountInfo{}, fmt.Errorf("%w: the token cannot read sys/mounts/%s", errPermissionDenied, mount)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return mountInfo{}, fmt.Errorf("vault returned status %d: %s", resp.StatusCode, string(body))
+	}
+
+	type mountEntry struct {
+		Type        string `json:"type"`
+		Accessor    string `json:"accessor"`
+		Description string `json:"description"`
+		Options     struct {
+			Version string `json:"version"`
+		} `json:"options"`
+	}
+
+	// Older Vault versions return the mount at the top level, newer ones
+	// under "data".
+	var result struct {
+		mountEntry
+		Data *mountEntry `json:"data"`
+	}
+	if err := json.Unmarshal(body, &result); err != nil {
+		return mountInfo{}, fmt.Errorf("failed to parse response: %w", err)
+	}
+	entry := result.mountEntry
+	if result.Data != nil && (result.Data.Type != "" || result.Data.Accessor != "") {
+		entry = *result.Data
+	}
+
+	info = mountInfo{
+		Path:        mount,
+		Type:        entry.Type,
+		Version:     entry.Options.Version,
+		Accessor:    entry.Accessor,
+		Description: entry.Description,
+	}
+
+	c.cache.mu.Lock()
+	if c.cache.mounts == nil {
+		c.cache.mounts = make(map[string]mountInfo)
+	}
+	c.cache.mounts[c.cacheKey(mount)] = info
+	c.cache.mu.Unlock()
+
+	return info, nil
+}
+
+// readSecret returns the current data at mount/path. When ReadCache is set,
+// concurrent and subsequent reads of the same path share a single request
+// until the path is written by this client.
+func (c *VaultClient) readSecret(ctx context.Context, mount, path string) (map[string]string, error) {
+	data, _, err := c.readSecretVersion(ctx, mount, path)
+	return data, err
+}
+
+// readSecretVersion is readSecret that also returns the KV version the data
+// belongs to, or 0 when the path does not exist.
+func (c *VaultClient) readSecretVersion(ctx context.Context, mount, path string) (map[string]string, int64, error) {
+	if c.FlattenUnder != "" {
+		// The caches hold top-level data, shared by every client.
+		return c.fetchSecretData(ctx, mount, path, 0)
+	}
+	if !c.ReadCache {
+		return c.fetchSecret(ctx, mount, path)
+	}
+
+	key := c.secretCacheKey(mount, path)
+
+	c.cache.mu.Lock()
+	entry, ok := c.cache.secretReads[key]
+	if !ok {
+		entry = &cachedRead{done: make(chan struct{})}
+		if c.cache.secretReads == nil {
+			c.cache.secretReads = make(map[string]*cachedRead)
+		}
+		c.cache.secretReads[key] = entry
+		c.cache.mu.Unlock()
+
+		entry.data, entry.version, entry.err = c.fetchSecret(ctx, mount, path)
+		close(entry.done)
+		if entry.err != nil {
+			c.invalidateSecret(mount, path)
+		}
+	} else {
+		c.cache.mu.Unlock()
+		<-entry.done
+	}
+
+	if entry.err != nil {
+		return nil, 0, entry.err
+	}
+
+	return mergeKeys(entry.data, nil), entry.version, nil
+}
+
+func (c *VaultClient) invalidateSecret(mount, path string) {
+	c.cache.mu.Lock()
+	delete(c.cache.secretReads, c.secretCacheKey(mount, path))
+	delete(c.cache.secretVersions, c.secretCacheKey(mount, path))
+	c.cache.mu.Unlock()
+}
+
+func (c *VaultClient) secretCacheKey(mount, path string) string {
+	return c.cacheKey(mount + "/" + path)
+}
+
+// cacheKey scopes a cache key to the client's namespace, as the same mount
+// and path name different secrets in different namespaces.
+func (c *VaultClient) cacheKey(key string) string {
+	if c.Namespace == "" {
+		return key
+	}
+	return c.Namespace + "::" + key
+}
+
+// fetchSecret reads mount/path from Vault. With ConditionalReads it first
+// checks the metadata version and reuses the last data read for that version;
+// any metadata failure falls back to a full read.
+func (c *VaultClient) fetchSecret(ctx context.Context, mount, path string) (map[string]string, int64, error) {
+	if !c.ConditionalReads {
+		return c.fetchSecretData(ctx, mount, path, 0)
+	}
+
+	key := c.secretCacheKey(mount, path)
+
+	c.cache.mu.Lock()
+	cached, ok := c.cache.secretVersions[key]
+	c.cache.mu.Unlock()
+
+	if ok {
+		metadata, err := c.readMetadata(ctx, mount, path)
+		if err == nil && metadata.CurrentVersion == cached.version {
+			return mergeKeys(cached.data, nil), cached.version, nil
+		}
+		if err != nil {
+			tflog.Debug(ctx, "Metadata read failed, falling back to full read", map[string]interface{}{
+				"mount": mount,
+				"path":  path,
+				"error": err.Error(),
+			})
+		}
+	}
+
+	data, version, err := c.fetchSecretData(ctx, mount, path, 0)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	if version > 0 {
+		c.cache.mu.Lock()
+		if c.cache.secretVersions == nil {
+			c.cache.secretVersions = make(map[string]versionedSecret)
+		}
+		c.cache.secretVersions[key] = versionedSecret{data: mergeKeys(data, nil), version: version}
+		c.cache.mu.Unlock()
+	}
+
+	return data, version, nil
+}
+
+// fetchSecretData reads mount/path from Vault, at the given KV v2 version or
+// the latest one when version is 0. With FlattenUnder set it returns the
+// fields of the object under that key.
+func (c *VaultClient) fetchSecretData(ctx context.Context, mount, path string, version int64) (map[string]string, int64, error) {
+	raw, version, err := c.fetchSecretRaw(ctx, mount, path, version)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	if c.FlattenUnder != "" {
+		nested, err := nestedObject(raw, c.FlattenUnder)
+		if err != nil {
+			return nil, 0, fmt.Errorf("%s/%s: %w", mount, path, err)
+		}
+		raw = nested
+	}
+
+	data := make(map[string]string)
+	for k, v := range raw {
+		data[k] = rawValueString(v)
+	}
+
+	return data, version, nil
+}
+
+// nestedObject returns the object stored under key in raw, or an empty one
+// when there is none.
+func nestedObject(raw map[string]interface{}, key string) (map[string]interface{}, error) {
+	value, ok := raw[key]
+	if !ok || value == nil {
+		return make(map[string]interface{}), nil
+	}
+	nested, ok := value.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("key %q holds %s, not an object, so it cannot be flattened", key, jsonTypeOf(value))
+	}
+	return nested, nil
+}
+
+// fetchSecretRaw is fetchSecretData returning the decoded top-level secret
+// data, or an empty map when the path does not exist.
+func (c *VaultClient) fetchSecretRaw(ctx context.Context, mount, path string, version int64) (map[string]interface{}, int64, error) {
+	apiPath := c.secretAPIPath(mount, path)
+	if version > 0 && !c.kvV1() {
+		apiPath = fmt.Sprintf("%s?version=%d", apiPath, version)
+	}
+
+	resp, body, err := c.getConsistent(ctx, apiPath)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	if resp.StatusCode == http.StatusNotFound {
+		if isMissingMount(body) {
+			return nil, 0, fmt.Errorf("%w: %s", errMountMissing, mount)
+		}
+		return make(map[string]interface{}), 0, nil
+	}
+
+	// Some gateways answer 204 for an empty secret instead of 404.
+	if resp.StatusCode == http.StatusNoContent {
+		return make(map[string]interface{}), 0, nil
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, 0, fmt.Errorf("vault returned status %d: %s", resp.StatusCode, string(body))
+	}
+
+	if info, held := controlGroupInfo(body); held {
+		if body, err = c.awaitControlGroup(ctx, info); err != nil {
+			return nil, 0, err
+		}
+	}
+
+	var raw map[string]interface{}
+
+	if c.kvV1() {
+		var result struct {
+			Data map[string]interface{} `json:"data"`
+		}
+		if err := json.Unmarshal(body, &result); err != nil {
+			return nil, 0, fmt.Errorf("failed to parse response: %w", err)
+		}
+		raw = result.Data
+	} else {
+		var result struct {
+			Data struct {
+				Data     map[string]interface{} `json:"data"`
+				Metadata struct {
+					Version int64 `json:"version"`
+				} `json:"metadata"`
+			} `json:"data"`
+		}
+		if err := json.Unmarshal(body, &result); err != nil {
+			return nil, 0, fmt.Errorf("failed to parse response: %w", err)
+		}
+		raw = result.Data.Data
+		version = result.Data.Metadata.Version
+	}
+
+	if c.ResponseDataPath != "" {
+		var doc interface{}
+		if err := json.Unmarshal(body, &doc); err != nil {
+			return nil, 0, fmt.Errorf("failed to parse response: %w", err)
+		}
+		value, _ := lookupJSONPath(doc, c.ResponseDataPath)
+		obj, ok := value.(map[string]interface{})
+		if !ok {
+			return nil, 0, fmt.Errorf("read response has no object at response_data_path %q", c.ResponseDataPath)
+		}
+		raw = obj
+	}
+	if raw == nil {
+		raw = make(map[string]interface{})
+	}
+
+	c.recordLease(mount, path, body)
+
+	return raw, version, nil
+}
+
+// secretLease is the lease information Vault returned with a read. KV v2
+// never sets it; KV v1 mounts with a ttl and wrapped or plugin-backed
+// responses may.
+type secretLease struct {
+	ID       string
+	Duration int64
+}
+
+func (c *VaultClient) recordLease(mount, path string, body []byte) {
+	var result struct {
+		LeaseID       string `json:"lease_id"`
+		LeaseDuration int64  `json:"lease_duration"`
+	}
+	_ = json.Unmarshal(body, &result)
+
+	key := c.secretCacheKey(mount, path)
+	c.cache.mu.Lock()
+	defer c.cache.mu.Unlock()
+	if result.LeaseID == "" && result.LeaseDuration == 0 {
+		delete(c.cache.leases, key)
+		return
+	}
+	if c.cache.leases == nil {
+		c.cache.leases = make(map[string]secretLease)
+	}
+	c.cache.leases[key] = secretLease{ID: result.LeaseID, Duration: result.LeaseDuration}
+}
+
+// secretLease returns the lease information of the last read of mount/path,
+// if it had any.
+func (c *VaultClient) secretLease(mount, path string) (secretLease, bool) {
+	c.cache.mu.Lock()
+	defer c.cache.mu.Unlock()
+	lease, ok := c.cache.leases[c.secretCacheKey(mount, path)]
+	return lease, ok
+}
+
+// getConsistent sends a GET for apiPath and returns the response with its
+// body already read. A 412, which a performance standby returns until it has
+// caught up with a recent write, is retried up to MaxRetries times.
+func (c *VaultClient) getConsistent(ctx context.Context, apiPath string) (*http.Response, []byte, error) {
+	for attempt := 0; ; attempt++ {
+		req, err := c.newRequest(ctx, "GET", apiPath, nil)
+		if err != nil {
+			return nil, nil, err
+		}
+		req.Header.Set("X-Vault-Request", "true")
+
+		resp, err := c.do(req)
+		if err != nil {
+			return nil, nil, err
+		}
+		body, err := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to read response: %w", err)
+		}
+
+		if resp.StatusCode != http.StatusPreconditionFailed || attempt >= c.MaxRetries {
+			return resp, body, nil
+		}
+
+		delay := c.Backoff.delay(attempt)
+		tflog.Warn(ctx, "Vault node has not caught up with recent writes yet, retrying read", map[string]interface{}{
+			"attempt": attempt + 1,
+			"delay":   delay.String(),
+		})
+		select {
+		case <-ctx.Done():
+			return nil, nil, ctx.Err()
+		case <-time.After(delay):
+		}
+	}
+}
+
+// secretAPIPath returns the API path used to read and write mount/path.
+func (c *VaultClient) secretAPIPath(mount, path string) string {
+	if c.kvV1() {
+		return fmt.Sprintf("%s/%s", mount, path)
+	}
+	return fmt.Sprintf("%s/data/%s", mount, path)
+}
+
+// wrapInfo is the wrap_info of a response-wrapped read.
+type wrapInfo struct {
+	Token        string `json:"token"`
+	Accessor     string `json:"accessor"`
+	TTL          int64  `json:"ttl"`
+	CreationTime string `json:"creation_time"`
+}
+
+// errSecretMissing is returned when a secret that must exist does not.
+var errSecretMissing = errors.New("secret does not exist")
+
+// wrapSecret reads mount/path with X-Vault-Wrap-TTL set, so Vault returns a
+// single-use wrapping token for the response instead of the secret itself.
+func (c *VaultClient) wrapSecret(ctx context.Context, mount, path string, ttl int64) (*wrapInfo, error) {
+	req, err := c.newRequest(ctx, "GET", c.secretAPIPath(mount, path), nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("X-Vault-Request", "true")
+	req.Header.Set("X-Vault-Wrap-TTL", strconv.FormatInt(ttl, 10))
+
+	resp, err := c.do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+
+	if resp.StatusCode == http.StatusNotFound {
+		if isMissingMount(body) {
+			return nil, fmt.Errorf("%w: %s", errMountMissing, mount)
+		}
+		return nil, fmt.Errorf("%w: %s/%s", errSecretMissing, mount, path)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("vault returned status %d: %s", resp.StatusCode, string(body))
+	}
+
+	var result struct {
+		WrapInfo *wrapInfo `json:"wrap_info"`
+	}
+	if err := json.Unmarshal(body, &result); err != nil {
+		return nil, fmt.Errorf("failed to parse response: %w", err)
+	}
+	if result.WrapInfo == nil || result.WrapInfo.Token == "" {
+		return nil, errors.New("vault did not wrap the response")
+	}
+	return result.WrapInfo, nil
+}
+
+// mountInfo describes a secrets engine mount as listed by sys/mounts.
+type mountInfo struct {
+	Path        string
+	Type        string
+	Version     string
+	Accessor    string
+	Description string
+}
+
+// listMounts returns every secrets engine mount visible to the token,
+// sorted by path.
+func (c *VaultClient) listMounts(ctx context.Context) ([]mountInfo, error) {
+	req, err := c.newRequest(ctx, "GET", "sys/mounts", nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("X-Vault-Request", "true")
+
+	resp, err := c.do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+
+	if resp.StatusCode == http.StatusForbidden {
+		return nil, fmt.Errorf("%w: the token cannot read sys/mounts", errPermissionDenied)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("vault returned status %d: %s", resp.StatusCode, string(body))
+	}
+
+	type mountEntry struct {
+		Type        string `json:"type"`
+		Accessor    string `json:"accessor"`
+		Description string `json:"description"`
+		Options     struct {
+			Version string `json:"version"`
+		} `json:"options"`
+	}
+
+	var result struct {
+		Data map[string]json.RawMessage `json:"data"`
+	}
+	if err := json.Unmarshal(body, &result); err != nil {
+		return nil, fmt.Errorf("failed to parse response: %w", err)
+	}
+
+	mounts := make([]mountInfo, 0, len(result.Data))
+	for path, raw := range result.Data {
+		var entry mountEntry
+		if err := json.Unmarshal(raw, &entry); err != nil || entry.Type == "" {
+			continue
+		}
+		mounts = append(mounts, mountInfo{
+			Path:        strings.TrimSuffix(path, "/"),
+			Type:        entry.Type,
+			Version:     entry.Options.Version,
+			Accessor:    entry.Accessor,
+			Description: entry.Description,
+		})
+	}
+
+	sort.Slice(mounts, func(i, j int) bool { return mounts[i].Path < mounts[j].Path })
+	return mounts, nil
+}
+
+// replicationStatus holds the replication modes reported by
+// sys/replication/status ("primary", "secondary", "disabled", ...).
+type replicationStatus struct {
+	DRMode          string
+	PerformanceMode string
+}
+
+// errReplicationUnsupported is returned when the server has no replication
+// endpoint, i.e. it is not a Vault Enterprise cluster.
+var errReplicationUnsupported = errors.New("replication status is not available: not an enterprise cluster")
+
+// readReplicationStatus returns the DR and performance replication modes of
+// the cluster the provider is talking to.
+func (c *VaultClient) readReplicationStatus(ctx context.Context) (*replicationStatus, error) {
+	req, err := c.newRequest(ctx, "GET", "sys/replication/status", nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("X-Vault-Request", "true")
+
+	resp, err := c.do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, errReplicationUnsupported
+	}
+
+	if resp.StatusCode == http.StatusForbidden {
+		return nil, fmt.Errorf("%w: the token cannot read sys/replication/status", errPermissionDenied)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("vault returned status %d: %s", resp.StatusCode, string(body))
+	}
+
+	var result struct {
+		Data struct {
+			DR struct {
+				Mode string `json:"mode"`
+			} `json:"dr"`
+			Performance struct {
+				Mode string `json:"mode"`
+			} `json:"performance"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(body, &result); err != nil {
+		return nil, fmt.Errorf("failed to parse response: %w", err)
+	}
+
+	return &replicationStatus{
+		DRMode:          result.Data.DR.Mode,
+		PerformanceMode: result.Data.Performance.Mode,
+	}, nil
+}
+
+// readMetadata returns the KV v2 metadata of mount/path. A missing path has
+// zero-valued metadata.
+func (c *VaultClient) readMetadata(ctx context.Context, mount, path string) (*secretMetadata, error) {
+	if c.kvV1() {
+		return nil, fmt.Errorf("secret metadata is only available on KV v2 mounts")
+	}
+
+	req, err := c.newRequest(ctx, "GET", fmt.Sprintf("%s/metadata/%s", mount, path), nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("X-Vault-Request", "true")
+
+	resp, err := c.do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+
+	if resp.StatusCode == http.StatusNotFound {
+		if isMissingMount(body) {
+			return nil, fmt.Errorf("%w: %s", errMountMissing, mount)
+		}
+		return &secretMetadata{}, nil
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("vault returned status %d: %s", resp.StatusCode, string(body))
+	}
+
+	var result struct {
+		Data secretMetadata `json:"data"`
+	}
+
+	if err := json.Unmarshal(body, &result); err != nil {
+		return nil, fmt.Errorf("failed to parse response: %w", err)
+	}
+
+	return &result.Data, nil
+}
+
+// defaultMaxVersions is how many versions KV v2 keeps when neither the path
+// nor the mount sets max_versions.
+const defaultMaxVersions = 10
+
+// mountMaxVersions returns the max_versions of a KV v2 mount's config, or
+// defaultMaxVersions when it is unset.
+func (c *VaultClient) mountMaxVersions(ctx context.Context, mount string) (int64, error) {
+	req, err := c.newRequest(ctx, "GET", fmt.Sprintf("%s/config", mount), nil)
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("X-Vault-Request", "true")
+
+	resp, err := c.do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return 0, fmt.Errorf("failed to read response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return 0, fmt.Errorf("vault returned status %d: %s", resp.StatusCode, string(body))
+	}
+
+	var result struct {
+		Data struct {
+			MaxVersions int64 `json:"max_versions"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(body, &result); err != nil {
+		return 0, fmt.Errorf("failed to parse response: %w", err)
+	}
+	if result.Data.MaxVersions == 0 {
+		return defaultMaxVersions, nil
+	}
+	return result.Data.MaxVersions, nil
+}
+
+// writeSecret replaces the data at mount/path and returns the KV version
+// created by the write (0 if Vault did not report one, or in dry-run mode).
+func (c *VaultClient) writeSecret(ctx context.Context, mount, path string, data map[string]string) (int64, error) {
+	return c.writeSecretCAS(ctx, mount, path, data, nil)
+}
+
+// errPermissionDenied is returned when Vault answers 403 to a request.
+var errPermissionDenied = errors.New("permission denied")
+
+// errMountMissing is returned when Vault has no secrets engine mounted at the
+// requested mount path, as opposed to the secret itself not existing.
+var errMountMissing = errors.New("the mount appears to have been disabled or moved")
+
+// isMissingMount reports whether a 404 body is Vault's router error for a
+// path no mount handles, rather than a missing secret.
+func isMissingMount(body []byte) bool {
+	return strings.Contains(string(body), "no handler for route")
+}
+
+// errPayloadTooLarge is returned when a write is rejected before sending
+// because its payload exceeds the configured size limit.
+var errPayloadTooLarge = errors.New("secret payload is too large")
+
+// isSizeLimitError reports whether a failed write was rejected for its size,
+// either by the listener (413) or by the storage backend.
+func isSizeLimitError(status int, body []byte) bool {
+	if status == http.StatusRequestEntityTooLarge {
+		return true
+	}
+	if status < 400 {
+		return false
+	}
+	msg := strings.ToLower(string(body))
+	return strings.Contains(msg, "too large") || (strings.Contains(msg, "exceeds") && strings.Contains(msg, "limit"))
+}
+
+// defaultMaxRequestBytes matches Vault's default max_request_size listener
+// setting of 32 MiB.
+const defaultMaxRequestBytes = 32 << 20
+
+// oversizeKeys returns the largest keys of data, with their encoded sizes,
+// whose removal would bring a payload of size bytes within limit. data holds
+// the values as they are sent, after value_types conversion.
+func oversizeKeys(data map[string]interface{}, size, limit int) []string {
+	type entry struct {
+		key  string
+		size int
+	}
+	entries := make([]entry, 0, len(data))
+	for key, value := range data {
+		encodedKey, _ := json.Marshal(key)
+		encodedValue, _ := json.Marshal(value)
+		// Quoted key, colon, quoted value and separating comma.
+		entries = append(entries, entry{key: key, size: len(encodedKey) + len(encodedValue) + 2})
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		if entries[i].size != entries[j].size {
+			return entries[i].size > entries[j].size
+		}
+		return entries[i].key < entries[j].key
+	})
+
+	var keys []string
+	for _, e := range entries {
+		if size <= limit {
+			break
+		}
+		keys = append(keys, fmt.Sprintf("%q (%d bytes)", e.key, e.size))
+		size -= e.size
+	}
+	return keys
+}
+
+// encodeBody marshals a request body as RequestJSONIndent and
+// RequestTrailingNewline ask for.
+func (c *VaultClient) encodeBody(v interface{}) ([]byte, error) {
+	var body []byte
+	var err error
+	if c.RequestJSONIndent {
+		body, err = json.MarshalIndent(v, "", "  ")
+	} else {
+		body, err = json.Marshal(v)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if c.RequestTrailingNewline {
+		body = append(body, '\n')
+	}
+	return body, nil
+}
+
+// errCASMismatch is returned by writeSecretCAS when the secret is no longer at
+// the expected version.
+var errCASMismatch = errors.New("check-and-set version did not match the current version")
+
+// writeSecretCAS is writeSecret with an optional check-and-set version: the
+// write only succeeds if the secret is still at *cas.
+func (c *VaultClient) writeSecretCAS(ctx context.Context, mount, path string, data map[string]string, cas *int64) (int64, error) {
+	if c.DryRun {
+		tflog.Info(ctx, "[dry run] Skipping write to Vault", map[string]interface{}{
+			"mount": mount,
+			"path":  path,
+			"keys":  keysOnly(data),
+		})
+		return 0, nil
+	}
+
+	contentAddressed := c.ContentAddressedWrites && !c.kvV1()
+	hash := contentHash(data)
+	if contentAddressed {
+		if version, ok := c.unchangedContentVersion(ctx, mount, path, hash); ok {
+			tflog.Info(ctx, "Secret already holds this content, skipping write", map[string]interface{}{
+				"mount":   mount,
+				"path":    path,
+				"version": version,
+			})
+			return version, nil
+		}
+	}
+
+	defer c.invalidateSecret(mount, path)
+
+	typed, err := typedData(data, c.ValueTypes)
+	if err != nil {
+		return 0, err
+	}
+	var secretData interface{} = typed
+	if c.FlattenUnder != "" {
+		raw, _, err := c.fetchSecretRaw(ctx, mount, path, 0)
+		if err != nil {
+			return 0, fmt.Errorf("could not read %s/%s to keep the siblings of %q: %w", mount, path, c.FlattenUnder, err)
+		}
+		if _, err := nestedObject(raw, c.FlattenUnder); err != nil {
+			return 0, fmt.Errorf("%s/%s: %w", mount, path, err)
+		}
+		raw[c.FlattenUnder] = secretData
+		secretData = raw
+	}
+
+	var payload interface{}
+	if c.kvV1() {
+		if cas != nil {
+			return 0, fmt.Errorf("check-and-set writes require a KV v2 mount")
+		}
+		payload = secretData
+	} else {
+		v2Payload := map[string]interface{}{
+			"data": secretData,
+		}
+		if cas != nil {
+			v2Payload["options"] = map[string]interface{}{
+				"cas": *cas,
+			}
+		}
+		payload = v2Payload
+	}
+
+	body, err := c.encodeBody(payload)
+	if err != nil {
+		return 0, fmt.Errorf("failed to marshal payload: %w", err)
+	}
+
+	if c.MaxRequestBytes > 0 && len(body) > c.MaxRequestBytes {
+		return 0, fmt.Errorf("%w: the payload for %s/%s is %d bytes, over the %d byte limit; "+
+			"the largest keys pushing it over are %s. Move them to another path with a separate "+
+			"vaultpatch_kv_keys resource, or raise max_request_bytes if the server's max_request_size allows it",
+			errPayloadTooLarge, mount, path, len(body), c.MaxRequestBytes,
+			strings.Join(oversizeKeys(typed, len(body), c.MaxRequestBytes), ", "))
+	}
+
+	// The limit applies to the data Vault stores: with flatten_under that
+	// includes the sibling keys kept around the managed object.
+	if c.MaxSecretBytes > 0 {
+		encoded, _ := json.Marshal(secretData)
+		if len(encoded) > c.MaxSecretBytes {
+			return 0, fmt.Errorf("%w: the data for %s/%s is %d bytes, over max_secret_bytes (%d); "+
+				"the largest keys pushing it over are %s. Split the secret by moving them to another path",
+				errPayloadTooLarge, mount, path, len(encoded), c.MaxSecretBytes,
+				strings.Join(oversizeKeys(typed, len(encoded), c.MaxSecretBytes), ", "))
+		}
+	}
+
+	req, err := c.newRequest(ctx, "POST", c.secretAPIPath(mount, path), bytes.NewBuffer(body))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	respBody, _ := io.ReadAll(resp.Body)
+
+	if isSizeLimitError(resp.StatusCode, respBody) {
+		return 0, fmt.Errorf("%w: Vault rejected the %d byte payload for %s/%s as exceeding its size limit "+
+			"(max_request_size, or the storage backend's max entry size). Split the secret by moving some keys "+
+			"to another path; set max_secret_bytes to catch this before sending: %s",
+			errPayloadTooLarge, len(body), mount, path, string(respBody))
+	}
+
+	if cas != nil && resp.StatusCode == http.StatusBadRequest && strings.Contains(string(respBody), "check-and-set") {
+		return 0, fmt.Errorf("%w: %s", errCASMismatch, string(respBody))
+	}
+
+	if resp.StatusCode == http.StatusNotFound && isMissingMount(respBody) {
+		return 0, fmt.Errorf("%w: %s", errMountMissing, mount)
+	}
+
+	if resp.StatusCode == http.StatusPreconditionFailed {
+		return 0, fmt.Errorf("vault returned status 412: the node that received the write is likely a performance "+
+			"standby that has not caught up with the active node. Retry the apply, or send writes to the active "+
+			"node by setting headers = { \"X-Vault-Forward\" = \"active-node\" } on the provider: %s", string(respBody))
+	}
+
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusNoContent {
+		return 0, fmt.Errorf("vault returned status %d: %s", resp.StatusCode, string(respBody))
+	}
+
+	if info, held := controlGroupInfo(respBody); held {
+		if respBody, err = c.awaitControlGroup(ctx, info); err != nil {
+			return 0, err
+		}
+	}
+
+	var result struct {
+		Data struct {
+			Version int64 `json:"version"`
+		} `json:"data"`
+	}
+	if len(respBody) > 0 {
+		if err := json.Unmarshal(respBody, &result); err != nil {
+			return 0, fmt.Errorf("failed to parse response: %w", err)
+		}
+	}
+
+	if c.VerifyWrite {
+		if err := c.verifyWrite(ctx, mount, path, data, result.Data.Version); err != nil {
+			return 0, err
+		}
+	}
+
+	if len(c.DefaultCustomMetadata)+len(c.CustomMetadata) > 0 && !c.kvV1() {
+		if err := c.applyCustomMetadata(ctx, mount, path); err != nil {
+			tflog.Warn(ctx, "Could not apply custom metadata", map[string]interface{}{
+				"mount": mount,
+				"path":  path,
+				"error": err.Error(),
+			})
+		}
+	}
+
+	if contentAddressed && result.Data.Version > 0 {
+		note := fmt.Sprintf("%d:%s", result.Data.Version, hash)
+		if err := c.updateCustomMetadata(ctx, mount, path, map[string]string{contentHashMetadataKey: note}); err != nil {
+			tflog.Warn(ctx, "Could not record content hash in custom metadata", map[string]interface{}{
+				"mount": mount,
+				"path":  path,
+				"error": err.Error(),
+			})
+		}
+	}
+
+	return result.Data.Version, nil
+}
+
+// deleteSecret deletes the latest version of mount/path (on KV v2 a soft
+// delete that keeps history; on KV v1 the secret itself). A 403 is reported
+// as errPermissionDenied.
+func (c *VaultClient) deleteSecret(ctx context.Context, mount, path string) error {
+	if c.DryRun {
+		tflog.Info(ctx, "[dry run] Skipping delete in Vault", map[string]interface{}{
+			"mount": mount,
+			"path":  path,
+		})
+		return nil
+	}
+
+	defer c.invalidateSecret(mount, path)
+
+	req, err := c.newRequest(ctx, "DELETE", c.secretAPIPath(mount, path), nil)
+	if err != nil {
+		return err
+	}
+
+	resp, err := c.do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusForbidden {
+		return fmt.Errorf("%w: the token cannot delete %s/%s", errPermissionDenied, mount, path)
+	}
+
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusNoContent {
+		respBody, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("vault returned status %d: %s", resp.StatusCode, string(respBody))
+	}
+
+	return nil
+}
+
+// undeleteVersion restores a soft-deleted version of mount/path (KV v2).
+func (c *VaultClient) undeleteVersion(ctx context.Context, mount, path string, version int64) error {
+	if c.DryRun {
+		tflog.Info(ctx, "[dry run] Skipping undelete in Vault", map[string]interface{}{
+			"mount":   mount,
+			"path":    path,
+			"version": version,
+		})
+		return nil
+	}
+
+	defer c.invalidateSecret(mount, path)
+
+	body, err := c.encodeBody(map[string]interface{}{
+		"versions": []int64{version},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to marshal payload: %w", err)
+	}
+
+	req, err := c.newRequest(ctx, "POST", fmt.Sprintf("%s/undelete/%s", mount, path), bytes.NewBuffer(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusForbidden {
+		return fmt.Errorf("%w: the token cannot undelete %s/%s", errPermissionDenied, mount, path)
+	}
+
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusNoContent {
+		respBody, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("vault returned status %d: %s", resp.StatusCode, string(respBody))
+	}
+
+	return nil
+}
+
+// contentHashMetadataKey is the custom_metadata field holding
+// "<version>:<hash>" of the last content written by this provider.
+const contentHashMetadataKey = "vaultpatch_content_hash"
+
+// contentHash returns a short, order-independent hash of a secret's data.
+func contentHash(data map[string]string) string {
+	// json.Marshal sorts map keys, so equal maps always encode identically.
+	encoded, _ := json.Marshal(data)
+	sum := sha256.Sum256(encoded)
+	return hex.EncodeToString(sum[:])[:16]
+}
+
+// unchangedContentVersion reports whether the current version of mount/path
+// is the one this provider last wrote with the given content hash. The hash
+// is only trusted for the version it was recorded against, so writes by other
+// tools always invalidate it.
+func (c *VaultClient) unchangedContentVersion(ctx context.Context, mount, path, hash string) (int64, bool) {
+	metadata, err := c.readMetadata(ctx, mount, path)
+	if err != nil {
+		tflog.Debug(ctx, "Could not read metadata for content-addressed write", map[string]interface{}{
+			"mount": mount,
+			"path":  path,
+			"error": err.Error(),
+		})
+		return 0, false
+	}
+
+	want := fmt.Sprintf("%d:%s", metadata.CurrentVersion, hash)
+	if metadata.CurrentVersion == 0 || metadata.CustomMetadata[contentHashMetadataKey] != want {
+		return 0, false
+	}
+	return metadata.CurrentVersion, true
+}
+
+// updateCustomMetadata merges fields into the custom_metadata of mount/path,
+// preserving fields set by others.
+func (c *VaultClient) updateCustomMetadata(ctx context.Context, mount, path string, fields map[string]string) error {
+	metadata, err := c.readMetadata(ctx, mount, path)
+	if err != nil {
+		return err
+	}
+
+	return c.postCustomMetadata(ctx, mount, path, mergeKeys(metadata.CustomMetadata, fields))
+}
+
+// applyCustomMetadata adds the DefaultCustomMetadata entries that mount/path
+// does not have yet and sets the CustomMetadata ones. Other existing entries
+// keep their value.
+func (c *VaultClient) applyCustomMetadata(ctx context.Context, mount, path string) error {
+	metadata, err := c.readMetadata(ctx, mount, path)
+	if err != nil {
+		return err
+	}
+
+	custom := mergeKeys(mergeKeys(c.DefaultCustomMetadata, metadata.CustomMetadata), c.CustomMetadata)
+	if len(custom) == len(metadata.CustomMetadata) && keysMatch(metadata.CustomMetadata, custom) {
+		return nil
+	}
+
+	return c.postCustomMetadata(ctx, mount, path, custom)
+}
+
+// postCustomMetadata replaces the custom_metadata of mount/path.
+func (c *VaultClient) postCustomMetadata(ctx context.Context, mount, path string, custom map[string]string) error {
+	body, err := c.encodeBody(map[string]interface{}{
+		"custom_metadata": custom,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to marshal payload: %w", err)
+	}
+
+	req, err := c.newRequest(ctx, "POST", fmt.Sprintf("%s/metadata/%s", mount, path), bytes.NewBuffer(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusNoContent {
+		respBody, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("vault returned status %d: %s", resp.StatusCode, string(respBody))
+	}
+
+	return nil
+}
+
+// verifyWrite reads back the version a write reported (or the latest data
+// when none was reported) and checks it holds exactly data, comparing typed
+// keys by value so a number read back as "1.5" matches "1.50". Mismatches
+// are reported by key name only.
+func (c *VaultClient) verifyWrite(ctx context.Context, mount, path string, data map[string]string, version int64) error {
+	stored, storedVersion, err := c.fetchSecretData(ctx, mount, path, version)
+	if err != nil {
+		return fmt.Errorf("write verification failed: could not read back %s/%s: %w", mount, path, err)
+	}
+
+	if version > 0 && storedVersion != version {
+		return fmt.Errorf("write verification failed: wrote version %d of %s/%s but read back version %d",
+			version, mount, path, storedVersion)
+	}
+
+	var mismatched []string
+	for key, want := range data {
+		if got, ok := stored[key]; !ok || !sameTypedValue(c.ValueTypes[key], want, got) {
+			mismatched = append(mismatched, key)
+		}
+	}
+	for key := range stored {
+		if _, ok := data[key]; !ok {
+			mismatched = append(mismatched, key)
+		}
+	}
+
+	if len(mismatched) > 0 {
+		sort.Strings(mismatched)
+		return fmt.Errorf("write verification failed: %s/%s does not hold the written values for: %s "+
+			"(a gateway may have accepted the request without storing it)", mount, path, strings.Join(mismatched, ", "))
+	}
+
+	return nil
+}
+
+// writeSecretMerged writes merge(existing) with check-and-set against
+// version. When another writer wins the race it waits, re-reads the secret
+// and re-applies merge, up to maxRetries more times, waiting as backoff
+// says in between so racing writers spread out.
+func (c *VaultClient) writeSecretMerged(ctx context.Context, mount, path string, existing map[string]string, version int64,
+	merge func(map[string]string) map[string]string, maxRetries int, backoff retryBackoff) (int64, error) {
+	for attempt := 0; ; attempt++ {
+		cas := version
+		written, err := c.writeSecretCAS(ctx, mount, path, merge(existing), &cas)
+		if err == nil || !errors.Is(err, errCASMismatch) {
+			return written, err
+		}
+		if attempt >= maxRetries {
+			return written, fmt.Errorf("%w (gave up after %d retries)", err, attempt)
+		}
+
+		delay := backoff.delay(attempt)
+		tflog.Info(ctx, "Secret changed concurrently, re-reading and merging again", map[string]interface{}{
+			"mount":   mount,
+			"path":    path,
+			"attempt": attempt + 1,
+			"delay":   delay.String(),
+		})
+		select {
+		case <-ctx.Done():
+			return 0, ctx.Err()
+		case <-time.After(delay):
+		}
+
+		existing, version, err = c.readSecretVersion(ctx, mount, path)
+		if err != nil {
+			return 0, err
+		}
+	}
+}
+
+// appRolePath returns the API path of an AppRole role under an auth mount.
+func appRolePath(authMount, name string) string {
+	return fmt.Sprintf("auth/%s/role/%s", authMount, name)
+}
+
+// readAppRole returns the settings of an AppRole role, or nil when the role
+// does not exist.
+func (c *VaultClient) readAppRole(ctx context.Context, authMount, name string) (map[string]interface{}, error) {
+	resp, body, err := c.getConsistent(ctx, appRolePath(authMount, name))
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode == http.StatusNotFound {
+		if isMissingMount(body) {
+			return nil, fmt.Errorf("%w: auth/%s", errMountMissing, authMount)
+		}
+		return nil, nil
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("vault returned status %d: %s", resp.StatusCode, string(body))
+	}
+
+	var result struct {
+		Data map[string]interface{} `json:"data"`
+	}
+	if err := json.Unmarshal(body, &result); err != nil {
+		return nil, fmt.Errorf("failed to parse response: %w", err)
+	}
+	return result.Data, nil
+}
+
+// readAppRoleID returns the role ID of an AppRole role.
+func (c *VaultClient) readAppRoleID(ctx context.Context, authMount, name string) (string, error) {
+	resp, body, err := c.getConsistent(ctx, appRolePath(authMount, name)+"/role-id")
+	if err != nil {
+		return "", err
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("vault returned status %d: %s", resp.StatusCode, string(body))
+	}
+
+	var result struct {
+		Data struct {
+			RoleID string `json:"role_id"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(body, &result); err != nil {
+		return "", fmt.Errorf("failed to parse response: %w", err)
+	}
+	return result.Data.RoleID, nil
+}
+
+// writeAppRole creates or updates an AppRole role. Only the given fields
+// are sent, so settings not managed here keep their current values.
+func (c *VaultClient) writeAppRole(ctx context.Context, authMount, name string, fields map[string]interface{}) error {
+	if c.DryRun {
+		tflog.Info(ctx, "[dry run] Skipping AppRole role write", map[string]interface{}{
+			"auth_mount": authMount,
+			"role_name":  name,
+		})
+		return nil
+	}
+
+	body, err := c.encodeBody(fields)
+	if err != nil {
+		return fmt.Errorf("failed to marshal payload: %w", err)
+	}
+
+	req, err := c.newRequest(ctx, "POST", appRolePath(authMount, name), bytes.NewBuffer(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	respBody, _ := io.ReadAll(resp.Body)
+
+	if resp.StatusCode == http.StatusNotFound && isMissingMount(respBody) {
+		return fmt.Errorf("%w: auth/%s", errMountMissing, authMount)
+	}
+
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusNoContent {
+		return fmt.Errorf("vault returned status %d: %s", resp.StatusCode, string(respBody))
+	}
+
+	return nil
+}
+
+// deleteAppRole deletes an AppRole role. Deleting a missing role succeeds.
+func (c *VaultClient) deleteAppRole(ctx context.Context, authMount, name string) error {
+	if c.DryRun {
+		tflog.Info(ctx, "[dry run] Skipping AppRole role delete", map[string]interface{}{
+			"auth_mount": authMount,
+			"role_name":  name,
+		})
+		return nil
+	}
+
+	req, err := c.newRequest(ctx, "DELETE", appRolePath(authMount, name), nil)
+	if err != nil {
+		return err
+	}
+
+	resp, err := c.do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusNoContent && resp.StatusCode != http.StatusNotFound {
+		respBody, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("vault returned status %d: %s", resp.StatusCode, string(respBody))
+	}
+
+	return nil
+}
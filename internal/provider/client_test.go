@@ -0,0 +1,547 @@
+package provider
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-log/tflogtest"
+)
+
+const testToken = "s.test-token"
+
+// newTestClient returns a client talking to a test server running handler.
+func newTestClient(t *testing.T, handler http.HandlerFunc) *VaultClient {
+	t.Helper()
+	srv := httptest.NewServer(handler)
+	t.Cleanup(srv.Close)
+	return &VaultClient{
+		Address:    srv.URL,
+		Token:      testToken,
+		HTTPClient: srv.Client(),
+		Backoff:    retryBackoff{Jitter: retryJitterNone},
+		cache:      &clientCache{},
+	}
+}
+
+// kvStore is an in-memory KV v2 mount for tests. Written data goes through
+// a JSON round trip, so numbers read back in Vault's canonical form.
+type kvStore struct {
+	t        *testing.T
+	mu       sync.Mutex
+	mount    string
+	versions map[string][]map[string]interface{}
+	custom   map[string]map[string]string
+
+	// customWrites counts custom_metadata updates.
+	customWrites int
+}
+
+func newKVStore(t *testing.T, mount string) *kvStore {
+	return &kvStore{
+		t:        t,
+		mount:    mount,
+		versions: make(map[string][]map[string]interface{}),
+		custom:   make(map[string]map[string]string),
+	}
+}
+
+// latest returns the current data at path, or nil when it was never written.
+func (s *kvStore) latest(path string) map[string]interface{} {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	versions := s.versions[path]
+	if len(versions) == 0 {
+		return nil
+	}
+	return versions[len(versions)-1]
+}
+
+func (s *kvStore) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if path, ok := strings.CutPrefix(r.URL.Path, "/v1/"+s.mount+"/metadata/"); ok {
+		s.serveMetadata(w, r, path)
+		return
+	}
+	path, ok := strings.CutPrefix(r.URL.Path, "/v1/"+s.mount+"/data/")
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	switch r.Method {
+	case http.MethodGet:
+		versions := s.versions[path]
+		version := len(versions)
+		if v := r.URL.Query().Get("version"); v != "" {
+			fmt.Sscan(v, &version)
+		}
+		if version < 1 || version > len(versions) {
+			http.NotFound(w, r)
+			return
+		}
+		writeJSON(s.t, w, map[string]interface{}{
+			"data": map[string]interface{}{
+				"data":     versions[version-1],
+				"metadata": map[string]interface{}{"version": version},
+			},
+		})
+	case http.MethodPost, http.MethodPut:
+		var payload struct {
+			Data map[string]interface{} `json:"data"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		s.versions[path] = append(s.versions[path], payload.Data)
+		writeJSON(s.t, w, map[string]interface{}{
+			"data": map[string]interface{}{"version": len(s.versions[path])},
+		})
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+func (s *kvStore) serveMetadata(w http.ResponseWriter, r *http.Request, path string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	switch r.Method {
+	case http.MethodGet:
+		if len(s.versions[path]) == 0 {
+			http.NotFound(w, r)
+			return
+		}
+		writeJSON(s.t, w, map[string]interface{}{
+			"data": map[string]interface{}{
+				"current_version": len(s.versions[path]),
+				"custom_metadata": s.custom[path],
+			},
+		})
+	case http.MethodPost:
+		var payload struct {
+			CustomMetadata map[string]string `json:"custom_metadata"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		s.custom[path] = payload.CustomMetadata
+		s.customWrites++
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+// dnsFailingTransport fails the first failures requests with a DNS error
+// before passing requests on to next.
+type dnsFailingTransport struct {
+	failures int
+	calls    int
+	next     http.RoundTripper
+}
+
+func (t *dnsFailingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	t.calls++
+	if t.calls <= t.failures {
+		return nil, &net.DNSError{Err: "no such host", Name: req.URL.Hostname(), IsNotFound: true}
+	}
+	return t.next.RoundTrip(req)
+}
+
+func TestDoRetriesDNSErrors(t *testing.T) {
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	})
+	transport := &dnsFailingTransport{failures: 2, next: client.HTTPClient.Transport}
+	client.HTTPClient.Transport = transport
+	client.DNSRetries = 2
+
+	req, err := client.newRequest(context.Background(), "GET", "sys/health", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := client.do(req)
+	if err != nil {
+		t.Fatalf("expected the request to succeed after retries, got %s", err)
+	}
+	resp.Body.Close()
+	if transport.calls != 3 {
+		t.Errorf("expected 3 attempts, got %d", transport.calls)
+	}
+}
+
+func TestDoGivesUpAfterDNSRetries(t *testing.T) {
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {})
+	transport := &dnsFailingTransport{failures: 10, next: client.HTTPClient.Transport}
+	client.HTTPClient.Transport = transport
+	client.DNSRetries = 2
+	client.TokenInQuery = true
+
+	var logs bytes.Buffer
+	ctx := tflogtest.RootLogger(context.Background(), &logs)
+	req, err := client.newRequest(ctx, "GET", "sys/health", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = client.do(req)
+	if err == nil || !isDNSError(err) {
+		t.Fatalf("expected a DNS error, got %v", err)
+	}
+	if transport.calls != 3 {
+		t.Errorf("expected 3 attempts, got %d", transport.calls)
+	}
+	if strings.Contains(err.Error(), testToken) {
+		t.Errorf("error leaks the token: %s", err)
+	}
+	if !strings.Contains(logs.String(), "retrying") {
+		t.Errorf("expected retry warnings in the log, got %q", logs.String())
+	}
+	if strings.Contains(logs.String(), testToken) {
+		t.Errorf("retry warnings leak the token: %s", logs.String())
+	}
+}
+
+func TestRedactURLError(t *testing.T) {
+	err := &url.Error{
+		Op:  "Get",
+		URL: "https://vault.example.com/v1/app/data/svc?token=" + testToken,
+		Err: &net.DNSError{Err: "no such host", Name: "vault.example.com"},
+	}
+	redactURLError(err)
+	if strings.Contains(err.Error(), testToken) {
+		t.Errorf("error still contains the token: %s", err)
+	}
+	if err.URL != "https://vault.example.com/v1/app/data/svc" {
+		t.Errorf("unexpected redacted URL %q", err.URL)
+	}
+
+	plain := errors.New("boom")
+	redactURLError(plain)
+	if plain.Error() != "boom" {
+		t.Errorf("non-URL error was changed: %s", plain)
+	}
+}
+
+func TestWriteSecretCASMaxSecretBytes(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("within limit", func(t *testing.T) {
+		store := newKVStore(t, "app")
+		client := newTestClient(t, store.ServeHTTP)
+		client.MaxSecretBytes = len(`{"A":"1234"}`)
+
+		if _, err := client.writeSecretCAS(ctx, "app", "svc", map[string]string{"A": "1234"}, nil); err != nil {
+			t.Fatal(err)
+		}
+		if store.latest("svc") == nil {
+			t.Error("expected the secret to be written")
+		}
+	})
+
+	t.Run("over limit names the largest keys", func(t *testing.T) {
+		store := newKVStore(t, "app")
+		client := newTestClient(t, store.ServeHTTP)
+		client.MaxSecretBytes = 40
+
+		data := map[string]string{"BIG": strings.Repeat("x", 40), "SMALL": "y"}
+		_, err := client.writeSecretCAS(ctx, "app", "svc", data, nil)
+		if !errors.Is(err, errPayloadTooLarge) {
+			t.Fatalf("expected errPayloadTooLarge, got %v", err)
+		}
+		if !strings.Contains(err.Error(), `"BIG" (49 bytes)`) || strings.Contains(err.Error(), `"SMALL"`) {
+			t.Errorf("expected only BIG to be named, got %s", err)
+		}
+		if store.latest("svc") != nil {
+			t.Error("an oversize secret was sent")
+		}
+	})
+
+	t.Run("measures value_types output", func(t *testing.T) {
+		store := newKVStore(t, "app")
+		client := newTestClient(t, store.ServeHTTP).withValueTypes(map[string]string{"PORT": valueTypeNumber})
+		// {"PORT":8080} fits; {"PORT":"8080"} would not.
+		client.MaxSecretBytes = len(`{"PORT":8080}`)
+
+		if _, err := client.writeSecretCAS(ctx, "app", "svc", map[string]string{"PORT": "8080"}, nil); err != nil {
+			t.Fatalf("typed data within the limit was rejected: %s", err)
+		}
+	})
+
+	t.Run("counts flatten_under siblings", func(t *testing.T) {
+		store := newKVStore(t, "app")
+		store.versions["svc"] = []map[string]interface{}{{"OTHER": strings.Repeat("z", 50)}}
+		client := newTestClient(t, store.ServeHTTP).withFlattenUnder("managed")
+		client.MaxSecretBytes = 40
+
+		_, err := client.writeSecretCAS(ctx, "app", "svc", map[string]string{"A": "1"}, nil)
+		if !errors.Is(err, errPayloadTooLarge) {
+			t.Fatalf("expected errPayloadTooLarge for the stored data with siblings, got %v", err)
+		}
+		if len(store.versions["svc"]) != 1 {
+			t.Error("an oversize secret was sent")
+		}
+	})
+}
+
+func TestWriteSecretCASMaxRequestBytes(t *testing.T) {
+	store := newKVStore(t, "app")
+	client := newTestClient(t, store.ServeHTTP)
+	client.MaxRequestBytes = 30
+
+	_, err := client.writeSecretCAS(context.Background(), "app", "svc", map[string]string{"BIG": strings.Repeat("x", 30)}, nil)
+	if !errors.Is(err, errPayloadTooLarge) {
+		t.Fatalf("expected errPayloadTooLarge, got %v", err)
+	}
+	if !strings.Contains(err.Error(), `"BIG"`) || !strings.Contains(err.Error(), "max_request_bytes") {
+		t.Errorf("unexpected error %s", err)
+	}
+}
+
+func TestWriteSecretCASServerSizeLimit(t *testing.T) {
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusRequestEntityTooLarge)
+	})
+
+	_, err := client.writeSecretCAS(context.Background(), "app", "svc", map[string]string{"A": "1"}, nil)
+	if !errors.Is(err, errPayloadTooLarge) {
+		t.Fatalf("expected errPayloadTooLarge for a 413, got %v", err)
+	}
+}
+
+// failingFirst answers the first n requests with status and passes the
+// rest to next, counting all of them in *requests.
+func failingFirst(n, status int, requests *int, next http.HandlerFunc) http.HandlerFunc {
+	var mu sync.Mutex
+	return func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		*requests++
+		fail := *requests <= n
+		mu.Unlock()
+		if fail {
+			w.WriteHeader(status)
+			fmt.Fprint(w, `{"errors":[]}`)
+			return
+		}
+		next(w, r)
+	}
+}
+
+func TestReadSecretRetriesStandby412(t *testing.T) {
+	store := newKVStore(t, "app")
+	store.versions["svc"] = []map[string]interface{}{{"A": "1"}}
+
+	var requests int
+	client := newTestClient(t, failingFirst(1, http.StatusPreconditionFailed, &requests, store.ServeHTTP))
+	client.MaxRetries = 3
+
+	data, err := client.readSecret(context.Background(), "app", "svc")
+	if err != nil {
+		t.Fatalf("expected the read to succeed after a 412, got %s", err)
+	}
+	if data["A"] != "1" {
+		t.Errorf("data = %v", data)
+	}
+	if requests != 2 {
+		t.Errorf("sent %d requests, want 2", requests)
+	}
+}
+
+func TestReadSecretGivesUpOnStandby412(t *testing.T) {
+	var requests int
+	client := newTestClient(t, failingFirst(100, http.StatusPreconditionFailed, &requests, nil))
+	client.MaxRetries = 2
+
+	_, err := client.readSecret(context.Background(), "app", "svc")
+	if err == nil || !strings.Contains(err.Error(), "vault returned status 412") {
+		t.Fatalf("expected a 412 error, got %v", err)
+	}
+	if requests != 3 {
+		t.Errorf("sent %d requests, want 3", requests)
+	}
+}
+
+func TestWriteSecretCASStandby412(t *testing.T) {
+	store := newKVStore(t, "app")
+	var requests int
+	client := newTestClient(t, failingFirst(1, http.StatusPreconditionFailed, &requests, store.ServeHTTP))
+	client.MaxRetries = 3
+
+	// Writes are not retried; the error says how to send them to the
+	// active node instead.
+	_, err := client.writeSecretCAS(context.Background(), "app", "svc", map[string]string{"A": "1"}, nil)
+	if err == nil {
+		t.Fatal("expected an error for a 412")
+	}
+	for _, want := range []string{"status 412", "performance standby", `"X-Vault-Forward" = "active-node"`} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("error %q does not mention %s", err, want)
+		}
+	}
+	if requests != 1 {
+		t.Errorf("sent %d requests, want 1", requests)
+	}
+
+	if _, err := client.writeSecretCAS(context.Background(), "app", "svc", map[string]string{"A": "1"}, nil); err != nil {
+		t.Fatalf("expected the next write to succeed, got %s", err)
+	}
+	if got := store.latest("svc"); got["A"] != "1" {
+		t.Errorf("stored %v", got)
+	}
+}
+
+func TestNewRequestHeaderPrecedence(t *testing.T) {
+	for _, mode := range []string{namespaceModeHeader, namespaceModePath} {
+		client := (&VaultClient{
+			Address:       "https://vault.example.com",
+			Token:         testToken,
+			Namespace:     "team-a",
+			NamespaceMode: mode,
+			Headers:       map[string]string{"X-Gateway-Route": "provider", "X-Vault-Token": "s.provider"},
+			cache:         &clientCache{},
+		}).withHeaders(map[string]string{
+			"X-Gateway-Route":   "resource",
+			"x-vault-token":     "s.resource",
+			"X-Vault-Namespace": "team-b",
+			"Authorization":     "Bearer s.resource",
+		})
+
+		req, err := client.newRequest(context.Background(), "GET", "app/data/svc", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		wantNamespace := "team-a"
+		if mode == namespaceModePath {
+			wantNamespace = ""
+		}
+		for name, want := range map[string]string{
+			"X-Gateway-Route":   "resource",
+			"X-Vault-Token":     testToken,
+			"X-Vault-Namespace": wantNamespace,
+			"Authorization":     "",
+		} {
+			if got := req.Header.Get(name); got != want {
+				t.Errorf("mode %q: %s = %q, want %q", mode, name, got, want)
+			}
+		}
+	}
+}
+
+func TestReadSecretNoContent(t *testing.T) {
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	data, version, err := client.readSecretVersion(context.Background(), "app", "svc")
+	if err != nil {
+		t.Fatalf("expected a 204 to read as an empty secret, got %s", err)
+	}
+	if len(data) != 0 || version != 0 {
+		t.Errorf("got data %v at version %d, want an empty secret", data, version)
+	}
+}
+
+func TestVerifyWrite(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("stored", func(t *testing.T) {
+		store := newKVStore(t, "app")
+		client := newTestClient(t, store.ServeHTTP)
+		client.VerifyWrite = true
+
+		if _, err := client.writeSecret(ctx, "app", "svc", map[string]string{"A": "1"}); err != nil {
+			t.Fatal(err)
+		}
+	})
+
+	t.Run("dropped by a gateway", func(t *testing.T) {
+		store := newKVStore(t, "app")
+		store.versions["svc"] = []map[string]interface{}{{"A": "stale-secret", "B": "kept"}}
+		client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+			if r.Method == http.MethodPost {
+				// Acknowledge the write without storing it.
+				writeJSON(t, w, map[string]interface{}{"data": map[string]interface{}{"version": 1}})
+				return
+			}
+			store.ServeHTTP(w, r)
+		})
+		client.VerifyWrite = true
+
+		_, err := client.writeSecret(ctx, "app", "svc", map[string]string{"A": "new-value", "C": "3"})
+		if err == nil {
+			t.Fatal("expected verification to fail")
+		}
+		if !strings.Contains(err.Error(), "A, B, C") {
+			t.Errorf("expected the mismatched keys to be named, got %s", err)
+		}
+		if strings.Contains(err.Error(), "new-value") || strings.Contains(err.Error(), "stale-secret") {
+			t.Errorf("error leaks values: %s", err)
+		}
+	})
+
+	t.Run("wrong version", func(t *testing.T) {
+		client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+			if r.Method == http.MethodPost {
+				writeJSON(t, w, map[string]interface{}{"data": map[string]interface{}{"version": 2}})
+				return
+			}
+			// A gateway that ignores ?version= and serves a stale version.
+			writeJSON(t, w, map[string]interface{}{
+				"data": map[string]interface{}{
+					"data":     map[string]interface{}{"A": "1"},
+					"metadata": map[string]interface{}{"version": 1},
+				},
+			})
+		})
+		client.VerifyWrite = true
+
+		_, err := client.writeSecret(ctx, "app", "svc", map[string]string{"A": "1"})
+		if err == nil || !strings.Contains(err.Error(), "read back version 1") {
+			t.Errorf("expected a version mismatch, got %v", err)
+		}
+	})
+}
+
+func TestWriteSecretCustomMetadata(t *testing.T) {
+	ctx := context.Background()
+	store := newKVStore(t, "app")
+	store.versions["svc"] = []map[string]interface{}{{"A": "0"}}
+	store.custom["svc"] = map[string]string{"team": "payments", "owner": "alice", "other": "kept"}
+
+	client := newTestClient(t, store.ServeHTTP).withCustomMetadata(map[string]string{"owner": "bob", "tier": "1"})
+	client.DefaultCustomMetadata = map[string]string{"team": "platform", "cost_center": "42", "tier": "3"}
+
+	if _, err := client.writeSecret(ctx, "app", "svc", map[string]string{"A": "1"}); err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]string{
+		"team":        "payments", // existing entries win over defaults
+		"cost_center": "42",       // missing defaults are added
+		"owner":       "bob",      // resource entries win over existing ones
+		"tier":        "1",        // and over defaults
+		"other":       "kept",
+	}
+	if got := store.custom["svc"]; !reflect.DeepEqual(got, want) {
+		t.Errorf("custom_metadata = %v, want %v", got, want)
+	}
+
+	writes := store.customWrites
+	if _, err := client.writeSecret(ctx, "app", "svc", map[string]string{"A": "2"}); err != nil {
+		t.Fatal(err)
+	}
+	if store.customWrites != writes {
+		t.Error("custom_metadata was rewritten although it already held every entry")
+	}
+}
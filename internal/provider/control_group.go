@@ -0,0 +1,136 @@
+package provider
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+// controlGroupWrapInfo is the wrap_info Vault Enterprise returns in place of
+// a result when the request is held by a Control Group until approved.
+type controlGroupWrapInfo struct {
+	Token        string `json:"token"`
+	Accessor     string `json:"accessor"`
+	CreationPath string `json:"creation_path"`
+}
+
+// errControlGroupPending is returned when a request needs Control Group
+// approval that was not granted (in time).
+var errControlGroupPending = errors.New("request is waiting for control group approval")
+
+// controlGroupPollInterval is how often the approval of a held request is
+// checked while waiting for it.
+const controlGroupPollInterval = 5 * time.Second
+
+// controlGroupInfo returns the wrap_info of a response that a Control Group
+// is holding.
+func controlGroupInfo(body []byte) (*controlGroupWrapInfo, bool) {
+	var result struct {
+		WrapInfo *controlGroupWrapInfo `json:"wrap_info"`
+	}
+	if err := json.Unmarshal(body, &result); err != nil || result.WrapInfo == nil || result.WrapInfo.Accessor == "" {
+		return nil, false
+	}
+	return result.WrapInfo, true
+}
+
+// awaitControlGroup waits up to ControlGroupWait for a held request to be
+// approved and returns the response it would have produced. Without a wait,
+// or when approval does not come in time, it fails with what approvers need.
+func (c *VaultClient) awaitControlGroup(ctx context.Context, info *controlGroupWrapInfo) ([]byte, error) {
+	pending := fmt.Errorf("%w: %s is protected by a Vault Control Group. Ask an approver to authorize request "+
+		"accessor %s (vault write sys/control-group/authorize accessor=%s), then re-run the apply, or set "+
+		"control_group_wait_seconds on the provider to wait for approval",
+		errControlGroupPending, info.CreationPath, info.Accessor, info.Accessor)
+	if c.ControlGroupWait <= 0 {
+		return nil, pending
+	}
+
+	tflog.Warn(ctx, "Request is held by a Vault Control Group, waiting for approval", map[string]interface{}{
+		"path":     info.CreationPath,
+		"accessor": info.Accessor,
+		"timeout":  c.ControlGroupWait.String(),
+	})
+
+	ctx, cancel := context.WithTimeout(ctx, c.ControlGroupWait)
+	defer cancel()
+	for {
+		approved, err := c.controlGroupApproved(ctx, info.Accessor)
+		if err != nil {
+			return nil, err
+		}
+		if approved {
+			return c.unwrap(ctx, info.Token)
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, pending
+		case <-time.After(controlGroupPollInterval):
+		}
+	}
+}
+
+// controlGroupApproved reports whether the held request with accessor has
+// been approved.
+func (c *VaultClient) controlGroupApproved(ctx context.Context, accessor string) (bool, error) {
+	body, err := c.postJSON(ctx, "sys/control-group/request", map[string]string{"accessor": accessor})
+	if err != nil {
+		return false, fmt.Errorf("failed to check control group approval: %w", err)
+	}
+
+	var result struct {
+		Data struct {
+			Approved bool `json:"approved"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(body, &result); err != nil {
+		return false, fmt.Errorf("failed to parse control group status: %w", err)
+	}
+	return result.Data.Approved, nil
+}
+
+// unwrap returns the response wrapped in token.
+func (c *VaultClient) unwrap(ctx context.Context, token string) ([]byte, error) {
+	body, err := c.postJSON(ctx, "sys/wrapping/unwrap", map[string]string{"token": token})
+	if err != nil {
+		return nil, fmt.Errorf("failed to unwrap the approved response: %w", err)
+	}
+	return body, nil
+}
+
+// postJSON sends payload to apiPath and returns the body of a 200 response.
+func (c *VaultClient) postJSON(ctx context.Context, apiPath string, payload interface{}) ([]byte, error) {
+	body, err := c.encodeBody(payload)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal payload: %w", err)
+	}
+
+	req, err := c.newRequest(ctx, "POST", apiPath, bytes.NewBuffer(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("vault returned status %d: %s", resp.StatusCode, string(respBody))
+	}
+	return respBody, nil
+}
@@ -0,0 +1,154 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+var _ datasource.DataSource = &KvAssertDataSource{}
+
+type KvAssertDataSource struct {
+	client *VaultClient
+}
+
+type KvAssertDataSourceModel struct {
+	Mount             types.String `tfsdk:"mount"`
+	Path              types.String `tfsdk:"path"`
+	Expected          types.Map    `tfsdk:"expected"`
+	Consistency       types.String `tfsdk:"consistency"`
+	MatchedKeys       types.Set    `tfsdk:"matched_keys"`
+	PathNormalization types.String `tfsdk:"path_normalization"`
+}
+
+func NewKvAssertDataSource() datasource.DataSource {
+	return &KvAssertDataSource{}
+}
+
+func (d *KvAssertDataSource) Metadata(_ context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_kv_assert"
+}
+
+func (d *KvAssertDataSource) Schema(_ context.Context, _ datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		Description: "Reads a Vault KV secret and fails the plan or apply unless the given keys hold the expected " +
+			"values, as a policy gate. Failures name the mismatched keys only, never expected or live values.",
+		Attributes: map[string]schema.Attribute{
+			"mount": schema.StringAttribute{
+				Description: "The mount path of the KV v2 secrets engine (e.g., 'app_demo').",
+				Required:    true,
+			},
+			"path": schema.StringAttribute{
+				Description: "The path within the mount where the secret lives (e.g., 'my-service/test').",
+				Required:    true,
+			},
+			"path_normalization": schema.StringAttribute{
+				Description: "How to treat 'mount' and 'path' values with duplicate slashes or '.' and '..' segments: " +
+					"'normalize' collapses and resolves them, 'error' rejects them. A '..' that climbs above the value " +
+					"itself is always rejected. Defaults to 'normalize'.",
+				Optional: true,
+			},
+			"expected": schema.MapAttribute{
+				Description: "The values the keys must hold. A null value asserts that the key does not exist.",
+				Required:    true,
+				Sensitive:   true,
+				ElementType: types.StringType,
+			},
+			"consistency": schema.StringAttribute{
+				Description: "'strong' makes the read wait for the serving node to have caught up with this run's writes " +
+					"(via X-Vault-Index, Vault Enterprise); 'eventual' accepts whatever a standby has, for throughput. " +
+					"Defaults to 'strong'.",
+				Optional: true,
+			},
+			"matched_keys": schema.SetAttribute{
+				Description: "The asserted keys, all of which matched.",
+				Computed:    true,
+				ElementType: types.StringType,
+			},
+		},
+	}
+}
+
+func (d *KvAssertDataSource) Configure(_ context.Context, req datasource.ConfigureRequest, resp *datasource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+
+	client, ok := req.ProviderData.(*VaultClient)
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Data Source Configure Type",
+			"Expected *VaultClient, got something else.",
+		)
+		return
+	}
+
+	d.client = client
+}
+
+func (d *KvAssertDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	var config KvAssertDataSourceModel
+	resp.Diagnostics.Append(req.Config.Get(ctx, &config)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	mount := config.Mount.ValueString()
+	path := config.Path.ValueString()
+	if addTrailingSlashError(&resp.Diagnostics, path, "") {
+		return
+	}
+
+	mount, path, ok := cleanSecretPaths(mount, path, config.PathNormalization.ValueString(), &resp.Diagnostics)
+	if !ok {
+		return
+	}
+
+	expected, absent := splitKeys(config.Expected)
+	ctx = d.client.maskValues(ctx, expected)
+
+	client, ok := readClient(d.client, config.Consistency, &resp.Diagnostics)
+	if !ok {
+		return
+	}
+
+	data, err := client.readSecret(ctx, mount, path)
+	if err != nil {
+		if errors.Is(err, errMountMissing) {
+			resp.Diagnostics.AddError(
+				"Mount Not Found",
+				fmt.Sprintf("Vault has no secrets engine at %q: the mount appears to have been disabled or moved.", mount),
+			)
+			return
+		}
+		resp.Diagnostics.AddError(
+			"Failed to Read Secret",
+			fmt.Sprintf("Could not read %s/%s: %s", mount, path, err),
+		)
+		return
+	}
+
+	if mismatched := driftedKeys(data, expected, absent, nil); len(mismatched) > 0 {
+		resp.Diagnostics.AddError(
+			"Secret Assertion Failed",
+			fmt.Sprintf("%d of %d asserted keys in %s/%s do not match: %s.",
+				len(mismatched), len(expected)+len(absent), mount, path, strings.Join(mismatched, ", ")),
+		)
+		return
+	}
+
+	matched := append(sortedKeys(expected), absent...)
+	matchedSet, diags := types.SetValueFrom(ctx, types.StringType, matched)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	config.MatchedKeys = matchedSet
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &config)...)
+}
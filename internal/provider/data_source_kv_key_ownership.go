@@ -0,0 +1,183 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+var _ datasource.DataSource = &KvKeyOwnershipDataSource{}
+
+type KvKeyOwnershipDataSource struct {
+	client *VaultClient
+}
+
+type KvKeyOwnershipDataSourceModel struct {
+	Mount             types.String `tfsdk:"mount"`
+	Path              types.String `tfsdk:"path"`
+	ManagedKeys       types.Set    `tfsdk:"managed_keys"`
+	ManagedPresent    types.Set    `tfsdk:"managed_present"`
+	ManagedMissing    types.Set    `tfsdk:"managed_missing"`
+	Foreign           types.Set    `tfsdk:"foreign"`
+	Consistency       types.String `tfsdk:"consistency"`
+	PathNormalization types.String `tfsdk:"path_normalization"`
+}
+
+func NewKvKeyOwnershipDataSource() datasource.DataSource {
+	return &KvKeyOwnershipDataSource{}
+}
+
+func (d *KvKeyOwnershipDataSource) Metadata(_ context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_kv_key_ownership"
+}
+
+func (d *KvKeyOwnershipDataSource) Schema(_ context.Context, _ datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		Description: "Compares the keys of a Vault KV v2 secret with a list of managed key names. Only key names are " +
+			"returned, never values. A path that does not exist reports every managed key as missing.",
+		Attributes: map[string]schema.Attribute{
+			"mount": schema.StringAttribute{
+				Description: "The mount path of the KV v2 secrets engine (e.g., 'app_demo').",
+				Required:    true,
+			},
+			"path": schema.StringAttribute{
+				Description: "The path within the mount where the secret lives (e.g., 'my-service/test').",
+				Required:    true,
+			},
+			"path_normalization": schema.StringAttribute{
+				Description: "How to treat 'mount' and 'path' values with duplicate slashes or '.' and '..' segments: " +
+					"'normalize' collapses and resolves them, 'error' rejects them. A '..' that climbs above the value " +
+					"itself is always rejected. Defaults to 'normalize'.",
+				Optional: true,
+			},
+			"managed_keys": schema.SetAttribute{
+				Description: "The key names considered managed.",
+				Required:    true,
+				ElementType: types.StringType,
+			},
+			"consistency": schema.StringAttribute{
+				Description: "'strong' makes the read wait for the serving node to have caught up with this run's writes " +
+					"(via X-Vault-Index, Vault Enterprise); 'eventual' accepts whatever a standby has, for throughput. " +
+					"Defaults to 'strong'.",
+				Optional: true,
+			},
+			"managed_present": schema.SetAttribute{
+				Description: "Managed keys that exist in the secret.",
+				Computed:    true,
+				ElementType: types.StringType,
+			},
+			"managed_missing": schema.SetAttribute{
+				Description: "Managed keys that do not exist in the secret.",
+				Computed:    true,
+				ElementType: types.StringType,
+			},
+			"foreign": schema.SetAttribute{
+				Description: "Keys that exist in the secret but are not managed.",
+				Computed:    true,
+				ElementType: types.StringType,
+			},
+		},
+	}
+}
+
+func (d *KvKeyOwnershipDataSource) Configure(_ context.Context, req datasource.ConfigureRequest, resp *datasource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+
+	client, ok := req.ProviderData.(*VaultClient)
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Data Source Configure Type",
+			"Expected *VaultClient, got something else.",
+		)
+		return
+	}
+
+	d.client = client
+}
+
+func (d *KvKeyOwnershipDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	var config KvKeyOwnershipDataSourceModel
+	resp.Diagnostics.Append(req.Config.Get(ctx, &config)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	mount := config.Mount.ValueString()
+	path := config.Path.ValueString()
+	if addTrailingSlashError(&resp.Diagnostics, path, "") {
+		return
+	}
+
+	mount, path, ok := cleanSecretPaths(mount, path, config.PathNormalization.ValueString(), &resp.Diagnostics)
+	if !ok {
+		return
+	}
+
+	var managed []string
+	resp.Diagnostics.Append(config.ManagedKeys.ElementsAs(ctx, &managed, false)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	client, ok := readClient(d.client, config.Consistency, &resp.Diagnostics)
+	if !ok {
+		return
+	}
+
+	data, err := client.readSecret(ctx, mount, path)
+	if err != nil {
+		if errors.Is(err, errMountMissing) {
+			resp.Diagnostics.AddError(
+				"Mount Not Found",
+				fmt.Sprintf("Vault has no secrets engine at %q: the mount appears to have been disabled or moved.", mount),
+			)
+			return
+		}
+		resp.Diagnostics.AddError(
+			"Failed to Read Secret",
+			fmt.Sprintf("Could not read %s/%s: %s", mount, path, err),
+		)
+		return
+	}
+
+	isManaged := make(map[string]bool, len(managed))
+	present := []string{}
+	missing := []string{}
+	for _, key := range managed {
+		isManaged[key] = true
+		if _, ok := data[key]; ok {
+			present = append(present, key)
+		} else {
+			missing = append(missing, key)
+		}
+	}
+	foreign := []string{}
+	for key := range data {
+		if !isManaged[key] {
+			foreign = append(foreign, key)
+		}
+	}
+	sort.Strings(foreign)
+
+	for target, keys := range map[*types.Set][]string{
+		&config.ManagedPresent: present,
+		&config.ManagedMissing: missing,
+		&config.Foreign:        foreign,
+	} {
+		value, diags := types.SetValueFrom(ctx, types.StringType, keys)
+		resp.Diagnostics.Append(diags...)
+		*target = value
+	}
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &config)...)
+}
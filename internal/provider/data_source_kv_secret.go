@@ -0,0 +1,303 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	tfpath "github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+var _ datasource.DataSource = &KvSecretDataSource{}
+
+type KvSecretDataSource struct {
+	client *VaultClient
+}
+
+type KvSecretDataSourceModel struct {
+	Mount       types.String         `tfsdk:"mount"`
+	Path        types.String         `tfsdk:"path"`
+	Fallback    types.String         `tfsdk:"fallback_path"`
+	ServedPath  types.String         `tfsdk:"served_path"`
+	ReadFields  types.List           `tfsdk:"read_fields"`
+	Data        types.Map            `tfsdk:"data"`
+	Entries     []KvSecretEntryModel `tfsdk:"entries"`
+	Version     types.Int64          `tfsdk:"version"`
+	Consistency types.String         `tfsdk:"consistency"`
+
+	LeaseID           types.String `tfsdk:"lease_id"`
+	LeaseDuration     types.Int64  `tfsdk:"lease_duration"`
+	PathNormalization types.String `tfsdk:"path_normalization"`
+}
+
+type KvSecretEntryModel struct {
+	Name  types.String `tfsdk:"name"`
+	Value types.String `tfsdk:"value"`
+}
+
+func NewKvSecretDataSource() datasource.DataSource {
+	return &KvSecretDataSource{}
+}
+
+func (d *KvSecretDataSource) Metadata(_ context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_kv_secret"
+}
+
+func (d *KvSecretDataSource) Schema(_ context.Context, _ datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		Description: "Reads all keys of a Vault KV v2 secret. A path that does not exist reads as an empty secret.",
+		Attributes: map[string]schema.Attribute{
+			"mount": schema.StringAttribute{
+				Description: "The mount path of the KV v2 secrets engine (e.g., 'app_demo').",
+				Required:    true,
+			},
+			"path": schema.StringAttribute{
+				Description: "The path within the mount where the secret lives (e.g., 'my-service/test').",
+				Required:    true,
+			},
+			"path_normalization": schema.StringAttribute{
+				Description: "How to treat 'mount' and 'path' values with duplicate slashes or '.' and '..' segments: " +
+					"'normalize' collapses and resolves them, 'error' rejects them. A '..' that climbs above the value " +
+					"itself is always rejected. Defaults to 'normalize'.",
+				Optional: true,
+			},
+			"fallback_path": schema.StringAttribute{
+				Description: "Path within the mount read instead when 'path' does not exist, e.g. a shared default that " +
+					"service-specific secrets override. When neither exists the result is empty and a warning is raised.",
+				Optional: true,
+			},
+			"served_path": schema.StringAttribute{
+				Description: "The path the data was read from: 'path' or 'fallback_path'. Null when neither exists.",
+				Computed:    true,
+			},
+			"read_fields": schema.ListAttribute{
+				Description: "Only keep these keys of the secret; all others are discarded before they reach state. " +
+					"A warning is raised for fields the secret does not have. Defaults to every key.",
+				Optional:    true,
+				ElementType: types.StringType,
+			},
+			"data": schema.MapAttribute{
+				Description: "The key-value pairs of the secret.",
+				Computed:    true,
+				Sensitive:   true,
+				ElementType: types.StringType,
+			},
+			"entries": schema.ListNestedAttribute{
+				Description: "The same key-value pairs as a list of objects sorted by name, for iterating with " +
+					"for_each while keeping each value sensitive.",
+				Computed: true,
+				NestedObject: schema.NestedAttributeObject{
+					Attributes: map[string]schema.Attribute{
+						"name": schema.StringAttribute{
+							Description: "The key name.",
+							Computed:    true,
+						},
+						"value": schema.StringAttribute{
+							Description: "The key value.",
+							Computed:    true,
+							Sensitive:   true,
+						},
+					},
+				},
+			},
+			"consistency": schema.StringAttribute{
+				Description: "'strong' makes the read wait for the serving node to have caught up with this run's writes " +
+					"(via X-Vault-Index, Vault Enterprise); 'eventual' accepts whatever a standby has, for throughput. " +
+					"Defaults to 'strong'.",
+				Optional: true,
+			},
+			"version": schema.Int64Attribute{
+				Description: "The KV version that was read. 0 when the path does not exist.",
+				Computed:    true,
+			},
+			"lease_id": schema.StringAttribute{
+				Description: "The lease ID Vault returned with the read, for coordinating with lease management. " +
+					"Null when the response carried no lease information, as with KV v2.",
+				Computed: true,
+			},
+			"lease_duration": schema.Int64Attribute{
+				Description: "The lease duration in seconds Vault returned with the read. Null when the response " +
+					"carried no lease information.",
+				Computed: true,
+			},
+		},
+	}
+}
+
+func (d *KvSecretDataSource) Configure(_ context.Context, req datasource.ConfigureRequest, resp *datasource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+
+	client, ok := req.ProviderData.(*VaultClient)
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Data Source Configure Type",
+			"Expected *VaultClient, got something else.",
+		)
+		return
+	}
+
+	d.client = client
+}
+
+const (
+	consistencyStrong   = "strong"
+	consistencyEventual = "eventual"
+)
+
+// readClient returns the client a data source reads with for its
+// consistency setting.
+func readClient(client *VaultClient, consistency types.String, diags *diag.Diagnostics) (*VaultClient, bool) {
+	switch consistency.ValueString() {
+	case "", consistencyStrong:
+		return client, true
+	case consistencyEventual:
+		return client.withEventualConsistency(), true
+	default:
+		diags.AddAttributeError(
+			tfpath.Root("consistency"),
+			"Invalid Consistency",
+			fmt.Sprintf("Must be %q or %q, got %q.", consistencyStrong, consistencyEventual, consistency.ValueString()),
+		)
+		return nil, false
+	}
+}
+
+func (d *KvSecretDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	var config KvSecretDataSourceModel
+	resp.Diagnostics.Append(req.Config.Get(ctx, &config)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	mount := config.Mount.ValueString()
+	path := config.Path.ValueString()
+	if addTrailingSlashError(&resp.Diagnostics, path, "") {
+		return
+	}
+
+	mount, path, ok := cleanSecretPaths(mount, path, config.PathNormalization.ValueString(), &resp.Diagnostics)
+	if !ok {
+		return
+	}
+
+	var fields []string
+	if !config.ReadFields.IsNull() {
+		resp.Diagnostics.Append(config.ReadFields.ElementsAs(ctx, &fields, false)...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+		seen := make(map[string]bool, len(fields))
+		for _, field := range fields {
+			if field == "" || seen[field] {
+				resp.Diagnostics.AddAttributeError(
+					tfpath.Root("read_fields"),
+					"Invalid Read Fields",
+					fmt.Sprintf("Field names must be non-empty and unique, got %q.", field),
+				)
+				return
+			}
+			seen[field] = true
+		}
+	}
+
+	client, ok := readClient(d.client, config.Consistency, &resp.Diagnostics)
+	if !ok {
+		return
+	}
+
+	data, version, err := client.readSecretVersion(ctx, mount, path)
+	servedPath := types.StringValue(path)
+	if err == nil && version == 0 && len(data) == 0 {
+		servedPath = types.StringNull()
+		if fallback := config.Fallback.ValueString(); fallback != "" {
+			fallback, ok := cleanSecretPathAttr("fallback_path", "Fallback Path", fallback,
+				config.PathNormalization.ValueString(), &resp.Diagnostics)
+			if !ok {
+				return
+			}
+			tflog.Debug(ctx, "Secret not found, reading fallback path", map[string]interface{}{
+				"mount":    mount,
+				"path":     path,
+				"fallback": fallback,
+			})
+			path = fallback
+			data, version, err = client.readSecretVersion(ctx, mount, path)
+			if err == nil && version == 0 && len(data) == 0 {
+				resp.Diagnostics.AddWarning(
+					"Secret Not Found",
+					fmt.Sprintf("Neither %s/%s nor the fallback %s/%s exists; the result is empty.",
+						mount, config.Path.ValueString(), mount, fallback),
+				)
+			} else {
+				servedPath = types.StringValue(path)
+			}
+		}
+	}
+	if err != nil {
+		if errors.Is(err, errMountMissing) {
+			resp.Diagnostics.AddError(
+				"Mount Not Found",
+				fmt.Sprintf("Vault has no secrets engine at %q: the mount appears to have been disabled or moved.", mount),
+			)
+			return
+		}
+		resp.Diagnostics.AddError(
+			"Failed to Read Secret",
+			fmt.Sprintf("Could not read %s/%s: %s", mount, path, err),
+		)
+		return
+	}
+	if fields != nil {
+		projected := make(map[string]string, len(fields))
+		var missing []string
+		for _, field := range fields {
+			if value, ok := data[field]; ok {
+				projected[field] = value
+			} else {
+				missing = append(missing, field)
+			}
+		}
+		if len(missing) > 0 {
+			resp.Diagnostics.AddAttributeWarning(
+				tfpath.Root("read_fields"),
+				"Fields Not Found",
+				fmt.Sprintf("%s/%s has no key named %s.", mount, path, strings.Join(missing, ", ")),
+			)
+		}
+		data = projected
+	}
+	ctx = d.client.maskValues(ctx, data)
+
+	dataValue, diags := types.MapValueFrom(ctx, types.StringType, data)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	config.Data = dataValue
+	config.Entries = make([]KvSecretEntryModel, 0, len(data))
+	for _, key := range sortedKeys(data) {
+		config.Entries = append(config.Entries, KvSecretEntryModel{
+			Name:  types.StringValue(key),
+			Value: types.StringValue(data[key]),
+		})
+	}
+	config.Version = types.Int64Value(version)
+	config.ServedPath = servedPath
+	config.LeaseID = types.StringNull()
+	config.LeaseDuration = types.Int64Null()
+	if lease, ok := client.secretLease(mount, path); ok {
+		config.LeaseID = types.StringValue(lease.ID)
+		config.LeaseDuration = types.Int64Value(lease.Duration)
+	}
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &config)...)
+}
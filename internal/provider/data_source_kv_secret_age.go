@@ -0,0 +1,203 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	tfpath "github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+var _ datasource.DataSource = &KvSecretAgeDataSource{}
+
+type KvSecretAgeDataSource struct {
+	client *VaultClient
+}
+
+type KvSecretAgeDataSourceModel struct {
+	Mount             types.String `tfsdk:"mount"`
+	Path              types.String `tfsdk:"path"`
+	MaxAgeDays        types.Int64  `tfsdk:"max_age_days"`
+	FailOnStale       types.Bool   `tfsdk:"fail_on_stale"`
+	CreatedTime       types.String `tfsdk:"created_time"`
+	UpdatedTime       types.String `tfsdk:"updated_time"`
+	AgeSeconds        types.Int64  `tfsdk:"age_seconds"`
+	AgeDays           types.Int64  `tfsdk:"age_days"`
+	Stale             types.Bool   `tfsdk:"stale"`
+	Consistency       types.String `tfsdk:"consistency"`
+	PathNormalization types.String `tfsdk:"path_normalization"`
+}
+
+func NewKvSecretAgeDataSource() datasource.DataSource {
+	return &KvSecretAgeDataSource{}
+}
+
+func (d *KvSecretAgeDataSource) Metadata(_ context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_kv_secret_age"
+}
+
+func (d *KvSecretAgeDataSource) Schema(_ context.Context, _ datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		Description: "Reports how long ago a Vault KV v2 secret was last written (from its metadata) and checks it " +
+			"against a maximum age, to enforce rotation deadlines. Needs read on the metadata endpoint.",
+		Attributes: map[string]schema.Attribute{
+			"mount": schema.StringAttribute{
+				Description: "The mount path of the KV v2 secrets engine (e.g., 'app_demo').",
+				Required:    true,
+			},
+			"path": schema.StringAttribute{
+				Description: "The path within the mount where the secret lives (e.g., 'my-service/test').",
+				Required:    true,
+			},
+			"path_normalization": schema.StringAttribute{
+				Description: "How to treat 'mount' and 'path' values with duplicate slashes or '.' and '..' segments: " +
+					"'normalize' collapses and resolves them, 'error' rejects them. A '..' that climbs above the value " +
+					"itself is always rejected. Defaults to 'normalize'.",
+				Optional: true,
+			},
+			"max_age_days": schema.Int64Attribute{
+				Description: "The maximum age in days of the latest version. An older secret raises a warning, or an " +
+					"error with 'fail_on_stale'. Unset means no check.",
+				Optional: true,
+			},
+			"fail_on_stale": schema.BoolAttribute{
+				Description: "Fail instead of warning when the secret is older than 'max_age_days'. Defaults to false.",
+				Optional:    true,
+			},
+			"consistency": schema.StringAttribute{
+				Description: "'strong' makes the read wait for the serving node to have caught up with this run's writes " +
+					"(via X-Vault-Index, Vault Enterprise); 'eventual' accepts whatever a standby has, for throughput. " +
+					"Defaults to 'strong'.",
+				Optional: true,
+			},
+			"created_time": schema.StringAttribute{
+				Description: "RFC 3339 time the secret was first created.",
+				Computed:    true,
+			},
+			"updated_time": schema.StringAttribute{
+				Description: "RFC 3339 time the latest version was written.",
+				Computed:    true,
+			},
+			"age_seconds": schema.Int64Attribute{
+				Description: "Seconds since the latest version was written.",
+				Computed:    true,
+			},
+			"age_days": schema.Int64Attribute{
+				Description: "Whole days since the latest version was written.",
+				Computed:    true,
+			},
+			"stale": schema.BoolAttribute{
+				Description: "Whether the secret is older than 'max_age_days'. Always false when it is unset.",
+				Computed:    true,
+			},
+		},
+	}
+}
+
+func (d *KvSecretAgeDataSource) Configure(_ context.Context, req datasource.ConfigureRequest, resp *datasource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+
+	client, ok := req.ProviderData.(*VaultClient)
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Data Source Configure Type",
+			"Expected *VaultClient, got something else.",
+		)
+		return
+	}
+
+	d.client = client
+}
+
+func (d *KvSecretAgeDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	var config KvSecretAgeDataSourceModel
+	resp.Diagnostics.Append(req.Config.Get(ctx, &config)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	mount := config.Mount.ValueString()
+	path := config.Path.ValueString()
+	if addTrailingSlashError(&resp.Diagnostics, path, "") {
+		return
+	}
+
+	mount, path, ok := cleanSecretPaths(mount, path, config.PathNormalization.ValueString(), &resp.Diagnostics)
+	if !ok {
+		return
+	}
+
+	if !config.MaxAgeDays.IsNull() && config.MaxAgeDays.ValueInt64() <= 0 {
+		resp.Diagnostics.AddAttributeError(
+			tfpath.Root("max_age_days"),
+			"Invalid Max Age",
+			fmt.Sprintf("'max_age_days' must be positive, got %d.", config.MaxAgeDays.ValueInt64()),
+		)
+		return
+	}
+
+	client, ok := readClient(d.client, config.Consistency, &resp.Diagnostics)
+	if !ok {
+		return
+	}
+
+	metadata, err := client.readMetadata(ctx, mount, path)
+	if err != nil {
+		if errors.Is(err, errMountMissing) {
+			resp.Diagnostics.AddError(
+				"Mount Not Found",
+				fmt.Sprintf("Vault has no secrets engine at %q: the mount appears to have been disabled or moved.", mount),
+			)
+			return
+		}
+		resp.Diagnostics.AddError(
+			"Failed to Read Secret Metadata",
+			fmt.Sprintf("Could not read the metadata of %s/%s: %s", mount, path, err),
+		)
+		return
+	}
+	if metadata.CurrentVersion == 0 {
+		resp.Diagnostics.AddError(
+			"Secret Not Found",
+			fmt.Sprintf("%s/%s does not exist, so its age cannot be checked.", mount, path),
+		)
+		return
+	}
+
+	updated, err := time.Parse(time.RFC3339Nano, metadata.UpdatedTime)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Invalid Secret Metadata",
+			fmt.Sprintf("The metadata of %s/%s has an unreadable updated_time %q: %s", mount, path, metadata.UpdatedTime, err),
+		)
+		return
+	}
+	age := time.Since(updated)
+	ageDays := int64(age / (24 * time.Hour))
+
+	config.CreatedTime = types.StringValue(metadata.CreatedTime)
+	config.UpdatedTime = types.StringValue(metadata.UpdatedTime)
+	config.AgeSeconds = types.Int64Value(int64(age.Seconds()))
+	config.AgeDays = types.Int64Value(ageDays)
+	config.Stale = types.BoolValue(false)
+
+	if !config.MaxAgeDays.IsNull() && age > time.Duration(config.MaxAgeDays.ValueInt64())*24*time.Hour {
+		config.Stale = types.BoolValue(true)
+		summary := "Secret Is Stale"
+		detail := fmt.Sprintf("%s/%s was last written %s (%d days ago), more than the allowed %d days. Rotate it.",
+			mount, path, metadata.UpdatedTime, ageDays, config.MaxAgeDays.ValueInt64())
+		if config.FailOnStale.ValueBool() {
+			resp.Diagnostics.AddError(summary, detail)
+			return
+		}
+		resp.Diagnostics.AddWarning(summary, detail)
+	}
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &config)...)
+}
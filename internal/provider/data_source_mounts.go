@@ -0,0 +1,137 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+var _ datasource.DataSource = &MountsDataSource{}
+
+type MountsDataSource struct {
+	client *VaultClient
+}
+
+type MountsDataSourceModel struct {
+	KVOnly types.Bool        `tfsdk:"kv_only"`
+	Mounts []MountEntryModel `tfsdk:"mounts"`
+}
+
+type MountEntryModel struct {
+	Path        types.String `tfsdk:"path"`
+	Type        types.String `tfsdk:"type"`
+	Version     types.String `tfsdk:"version"`
+	Accessor    types.String `tfsdk:"accessor"`
+	Description types.String `tfsdk:"description"`
+}
+
+func NewMountsDataSource() datasource.DataSource {
+	return &MountsDataSource{}
+}
+
+func (d *MountsDataSource) Metadata(_ context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_mounts"
+}
+
+func (d *MountsDataSource) Schema(_ context.Context, _ datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		Description: "Lists the secrets engine mounts visible to the provider token (from sys/mounts). " +
+			"The token needs read access on sys/mounts.",
+		Attributes: map[string]schema.Attribute{
+			"kv_only": schema.BoolAttribute{
+				Description: "Only return KV mounts. Defaults to false.",
+				Optional:    true,
+			},
+			"mounts": schema.ListNestedAttribute{
+				Description: "The mounts, sorted by path.",
+				Computed:    true,
+				NestedObject: schema.NestedAttributeObject{
+					Attributes: map[string]schema.Attribute{
+						"path": schema.StringAttribute{
+							Description: "The mount path, without trailing slash.",
+							Computed:    true,
+						},
+						"type": schema.StringAttribute{
+							Description: "The secrets engine type (e.g., 'kv').",
+							Computed:    true,
+						},
+						"version": schema.StringAttribute{
+							Description: "The KV version from the mount options ('1' or '2'); empty for other engines.",
+							Computed:    true,
+						},
+						"accessor": schema.StringAttribute{
+							Description: "The mount accessor.",
+							Computed:    true,
+						},
+						"description": schema.StringAttribute{
+							Description: "The mount description.",
+							Computed:    true,
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func (d *MountsDataSource) Configure(_ context.Context, req datasource.ConfigureRequest, resp *datasource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+
+	client, ok := req.ProviderData.(*VaultClient)
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Data Source Configure Type",
+			"Expected *VaultClient, got something else.",
+		)
+		return
+	}
+
+	d.client = client
+}
+
+func (d *MountsDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	var config MountsDataSourceModel
+	resp.Diagnostics.Append(req.Config.Get(ctx, &config)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	mounts, err := d.client.listMounts(ctx)
+	if err != nil {
+		if errors.Is(err, errPermissionDenied) {
+			resp.Diagnostics.AddError(
+				"Permission Denied Listing Mounts",
+				"The provider token is not allowed to read sys/mounts. Grant it 'read' on the 'sys/mounts' path "+
+					"in its policy, or remove the vaultpatch_mounts data source.",
+			)
+			return
+		}
+		resp.Diagnostics.AddError(
+			"Failed to List Mounts",
+			fmt.Sprintf("Could not read sys/mounts: %s", err),
+		)
+		return
+	}
+
+	config.Mounts = make([]MountEntryModel, 0, len(mounts))
+	for _, mount := range mounts {
+		if config.KVOnly.ValueBool() && mount.Type != "kv" {
+			continue
+		}
+		config.Mounts = append(config.Mounts, MountEntryModel{
+			Path:        types.StringValue(mount.Path),
+			Type:        types.StringValue(mount.Type),
+			Version:     types.StringValue(mount.Version),
+			Accessor:    types.StringValue(mount.Accessor),
+			Description: types.StringValue(mount.Description),
+		})
+	}
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &config)...)
+}
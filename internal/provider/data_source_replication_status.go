@@ -0,0 +1,111 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+var _ datasource.DataSource = &ReplicationStatusDataSource{}
+
+type ReplicationStatusDataSource struct {
+	client *VaultClient
+}
+
+type ReplicationStatusDataSourceModel struct {
+	Enterprise      types.Bool   `tfsdk:"enterprise"`
+	DRMode          types.String `tfsdk:"dr_mode"`
+	PerformanceMode types.String `tfsdk:"performance_mode"`
+	Writable        types.Bool   `tfsdk:"writable"`
+}
+
+func NewReplicationStatusDataSource() datasource.DataSource {
+	return &ReplicationStatusDataSource{}
+}
+
+func (d *ReplicationStatusDataSource) Metadata(_ context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_replication_status"
+}
+
+func (d *ReplicationStatusDataSource) Schema(_ context.Context, _ datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		Description: "Reports the replication role of the Vault cluster the provider talks to (from sys/replication/status), " +
+			"so modules can tell whether writes are possible against this endpoint. " +
+			"On non-Enterprise Vault 'enterprise' is false and the modes are null.",
+		Attributes: map[string]schema.Attribute{
+			"enterprise": schema.BoolAttribute{
+				Description: "Whether the cluster exposes replication status, i.e. is Vault Enterprise.",
+				Computed:    true,
+			},
+			"dr_mode": schema.StringAttribute{
+				Description: "The disaster recovery replication mode: 'primary', 'secondary', or 'disabled'.",
+				Computed:    true,
+			},
+			"performance_mode": schema.StringAttribute{
+				Description: "The performance replication mode: 'primary', 'secondary', or 'disabled'.",
+				Computed:    true,
+			},
+			"writable": schema.BoolAttribute{
+				Description: "False when the cluster is a DR secondary, which cannot serve KV requests. " +
+					"Performance secondaries forward KV writes to their primary and count as writable.",
+				Computed: true,
+			},
+		},
+	}
+}
+
+func (d *ReplicationStatusDataSource) Configure(_ context.Context, req datasource.ConfigureRequest, resp *datasource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+
+	client, ok := req.ProviderData.(*VaultClient)
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Data Source Configure Type",
+			"Expected *VaultClient, got something else.",
+		)
+		return
+	}
+
+	d.client = client
+}
+
+func (d *ReplicationStatusDataSource) Read(ctx context.Context, _ datasource.ReadRequest, resp *datasource.ReadResponse) {
+	state := ReplicationStatusDataSourceModel{
+		Enterprise:      types.BoolValue(false),
+		DRMode:          types.StringNull(),
+		PerformanceMode: types.StringNull(),
+		Writable:        types.BoolValue(true),
+	}
+
+	status, err := d.client.readReplicationStatus(ctx)
+	switch {
+	case errors.Is(err, errReplicationUnsupported):
+		// Open source Vault has no replication; the single cluster is writable.
+	case errors.Is(err, errPermissionDenied):
+		resp.Diagnostics.AddError(
+			"Permission Denied Reading Replication Status",
+			"The provider token is not allowed to read sys/replication/status. Grant it 'read' on that path "+
+				"in its policy, or remove the vaultpatch_replication_status data source.",
+		)
+		return
+	case err != nil:
+		resp.Diagnostics.AddError(
+			"Failed to Read Replication Status",
+			fmt.Sprintf("Could not read sys/replication/status: %s", err),
+		)
+		return
+	default:
+		state.Enterprise = types.BoolValue(true)
+		state.DRMode = types.StringValue(status.DRMode)
+		state.PerformanceMode = types.StringValue(status.PerformanceMode)
+		state.Writable = types.BoolValue(status.DRMode != "secondary")
+	}
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &state)...)
+}
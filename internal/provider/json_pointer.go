@@ -0,0 +1,214 @@
+package provider
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// parseJSONPointer splits an RFC 6901 JSON Pointer into unescaped reference
+// tokens. The empty pointer (whole document) is not accepted since a
+// structured key is always updated field by field.
+func parseJSONPointer(pointer string) ([]string, error) {
+	if pointer == "" || pointer[0] != '/' {
+		return nil, fmt.Errorf("JSON pointer %q must start with '/'", pointer)
+	}
+
+	tokens := strings.Split(pointer[1:], "/")
+	for i, token := range tokens {
+		if strings.Contains(strings.ReplaceAll(strings.ReplaceAll(token, "~0", ""), "~1", ""), "~") {
+			return nil, fmt.Errorf("JSON pointer %q has an invalid '~' escape", pointer)
+		}
+		tokens[i] = strings.ReplaceAll(strings.ReplaceAll(token, "~1", "/"), "~0", "~")
+	}
+	return tokens, nil
+}
+
+// getJSONPointer returns the value at pointer in doc.
+func getJSONPointer(doc interface{}, tokens []string) (interface{}, bool) {
+	current := doc
+	for _, token := range tokens {
+		switch node := current.(type) {
+		case map[string]interface{}:
+			next, ok := node[token]
+			if !ok {
+				return nil, false
+			}
+			current = next
+		case []interface{}:
+			idx, err := strconv.Atoi(token)
+			if err != nil || idx < 0 || idx >= len(node) {
+				return nil, false
+			}
+			current = node[idx]
+		default:
+			return nil, false
+		}
+	}
+	return current, true
+}
+
+// setJSONPointer sets the value at pointer in doc, creating intermediate
+// objects as needed, and returns the updated document.
+func setJSONPointer(doc interface{}, tokens []string, value interface{}) (interface{}, error) {
+	if len(tokens) == 0 {
+		return value, nil
+	}
+
+	token := tokens[0]
+	switch node := doc.(type) {
+	case nil:
+		child, err := setJSONPointer(nil, tokens[1:], value)
+		if err != nil {
+			return nil, err
+		}
+		return map[string]interface{}{token: child}, nil
+	case map[string]interface{}:
+		child, err := setJSONPointer(node[token], tokens[1:], value)
+		if err != nil {
+			return nil, err
+		}
+		node[token] = child
+		return node, nil
+	case []interface{}:
+		idx, err := strconv.Atoi(token)
+		if err != nil || idx < 0 || idx >= len(node) {
+			return nil, fmt.Errorf("array index %q is out of range", token)
+		}
+		child, err := setJSONPointer(node[idx], tokens[1:], value)
+		if err != nil {
+			return nil, err
+		}
+		node[idx] = child
+		return node, nil
+	default:
+		return nil, fmt.Errorf("cannot descend into %q: the value there is not an object or array", token)
+	}
+}
+
+// removeJSONPointer deletes the value at pointer from doc, if present.
+// Only object members can be removed; array elements are left in place.
+func removeJSONPointer(doc interface{}, tokens []string) {
+	parent, ok := getJSONPointer(doc, tokens[:len(tokens)-1])
+	if !ok {
+		return
+	}
+	if obj, ok := parent.(map[string]interface{}); ok {
+		delete(obj, tokens[len(tokens)-1])
+	}
+}
+
+// decodeStructuredValue parses the JSON held by a key. An absent or empty
+// key decodes as an empty object.
+func decodeStructuredValue(raw string, exists bool) (interface{}, error) {
+	if !exists || raw == "" {
+		return map[string]interface{}{}, nil
+	}
+	var doc interface{}
+	if err := json.Unmarshal([]byte(raw), &doc); err != nil {
+		return nil, fmt.Errorf("value is not valid JSON: %w", err)
+	}
+	return doc, nil
+}
+
+// jsonPointerValue renders the value found at a pointer the way it is
+// stored in state: strings as-is, anything else as JSON text.
+func jsonPointerValue(value interface{}) string {
+	if str, ok := value.(string); ok {
+		return str
+	}
+	encoded, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprintf("%v", value)
+	}
+	return string(encoded)
+}
+
+// jsonEncoding controls how structured keys are re-encoded after their
+// fields change, so the stored text matches what consumers expect.
+type jsonEncoding struct {
+	Indent     string
+	EscapeHTML bool
+}
+
+// defaultJSONEncoding matches json.Marshal: compact, with <, > and &
+// escaped.
+var defaultJSONEncoding = jsonEncoding{EscapeHTML: true}
+
+// marshal encodes v without the trailing newline json.Encoder adds.
+func (e jsonEncoding) marshal(v interface{}) (string, error) {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(e.EscapeHTML)
+	enc.SetIndent("", e.Indent)
+	if err := enc.Encode(v); err != nil {
+		return "", err
+	}
+	return strings.TrimSuffix(buf.String(), "\n"), nil
+}
+
+// applyJSONPointers sets (or, for remove, deletes) the given pointer fields
+// inside the structured keys of data, re-encoding each touched key with enc.
+func applyJSONPointers(data map[string]string, fields map[string]map[string]string, remove map[string][]string, enc jsonEncoding) error {
+	touched := make(map[string]interface{})
+
+	load := func(key string) (interface{}, error) {
+		if doc, ok := touched[key]; ok {
+			return doc, nil
+		}
+		raw, exists := data[key]
+		doc, err := decodeStructuredValue(raw, exists)
+		if err != nil {
+			return nil, fmt.Errorf("key %q: %w", key, err)
+		}
+		return doc, nil
+	}
+
+	for key, pointers := range remove {
+		if _, exists := data[key]; !exists {
+			continue
+		}
+		doc, err := load(key)
+		if err != nil {
+			return err
+		}
+		for _, pointer := range pointers {
+			tokens, err := parseJSONPointer(pointer)
+			if err != nil {
+				return fmt.Errorf("key %q: %w", key, err)
+			}
+			removeJSONPointer(doc, tokens)
+		}
+		touched[key] = doc
+	}
+
+	for key, pointers := range fields {
+		doc, err := load(key)
+		if err != nil {
+			return err
+		}
+		for pointer, value := range pointers {
+			tokens, err := parseJSONPointer(pointer)
+			if err != nil {
+				return fmt.Errorf("key %q: %w", key, err)
+			}
+			doc, err = setJSONPointer(doc, tokens, value)
+			if err != nil {
+				return fmt.Errorf("key %q, pointer %q: %w", key, pointer, err)
+			}
+		}
+		touched[key] = doc
+	}
+
+	for key, doc := range touched {
+		encoded, err := enc.marshal(doc)
+		if err != nil {
+			return fmt.Errorf("key %q: failed to encode value: %w", key, err)
+		}
+		data[key] = encoded
+	}
+
+	return nil
+}
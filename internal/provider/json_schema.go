@@ -0,0 +1,322 @@
+package provider
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// jsonSchema is the subset of JSON Schema that values can be checked
+// against: type, enum, const, properties, required, additionalProperties,
+// items, minItems/maxItems, minLength/maxLength, pattern and
+// minimum/maximum. Annotations (title, description, $schema, ...) are
+// accepted and ignored; any other keyword is rejected, so a schema never
+// appears to enforce a rule that is not checked.
+type jsonSchema struct {
+	Type                 jsonSchemaTypes        `json:"type"`
+	Enum                 []interface{}          `json:"enum"`
+	Const                *interface{}           `json:"const"`
+	Properties           map[string]*jsonSchema `json:"properties"`
+	Required             []string               `json:"required"`
+	AdditionalProperties json.RawMessage        `json:"additionalProperties"`
+	Items                *jsonSchema            `json:"items"`
+	MinItems             *int                   `json:"minItems"`
+	MaxItems             *int                   `json:"maxItems"`
+	MinLength            *int                   `json:"minLength"`
+	MaxLength            *int                   `json:"maxLength"`
+	Pattern              string                 `json:"pattern"`
+	Minimum              *float64               `json:"minimum"`
+	Maximum              *float64               `json:"maximum"`
+
+	pattern *regexp.Regexp
+
+	// additional is the schema for properties not listed in Properties. Nil
+	// allows anything; noAdditional rejects them.
+	additional   *jsonSchema
+	noAdditional bool
+
+	// unsupported lists the keywords that are neither checked nor
+	// annotations, reported by compile.
+	unsupported []string
+}
+
+// jsonSchemaKeywords are the keywords jsonSchema checks.
+var jsonSchemaKeywords = map[string]bool{
+	"type": true, "enum": true, "const": true, "properties": true, "required": true,
+	"additionalProperties": true, "items": true, "minItems": true, "maxItems": true,
+	"minLength": true, "maxLength": true, "pattern": true, "minimum": true, "maximum": true,
+}
+
+// jsonSchemaAnnotations are keywords that do not constrain values.
+var jsonSchemaAnnotations = map[string]bool{
+	"$schema": true, "$id": true, "$comment": true, "title": true, "description": true,
+	"default": true, "examples": true, "deprecated": true, "readOnly": true, "writeOnly": true,
+}
+
+func (s *jsonSchema) UnmarshalJSON(raw []byte) error {
+	type plain jsonSchema
+	if err := json.Unmarshal(raw, (*plain)(s)); err != nil {
+		return err
+	}
+	var keywords map[string]json.RawMessage
+	if err := json.Unmarshal(raw, &keywords); err != nil {
+		return err
+	}
+	for name, value := range keywords {
+		switch {
+		case name == "const":
+			// A pointer field reads "const": null as absent.
+			var constant interface{}
+			if err := json.Unmarshal(value, &constant); err != nil {
+				return err
+			}
+			s.Const = &constant
+		case !jsonSchemaKeywords[name] && !jsonSchemaAnnotations[name]:
+			s.unsupported = append(s.unsupported, name)
+		}
+	}
+	sort.Strings(s.unsupported)
+	return nil
+}
+
+// jsonSchemaTypes accepts "type" as either a single name or a list of names.
+type jsonSchemaTypes []string
+
+func (t *jsonSchemaTypes) UnmarshalJSON(raw []byte) error {
+	var single string
+	if err := json.Unmarshal(raw, &single); err == nil {
+		*t = jsonSchemaTypes{single}
+		return nil
+	}
+	var list []string
+	if err := json.Unmarshal(raw, &list); err != nil {
+		return fmt.Errorf("\"type\" must be a string or a list of strings")
+	}
+	*t = list
+	return nil
+}
+
+var jsonSchemaTypeNames = map[string]bool{
+	"object": true, "array": true, "string": true, "number": true,
+	"integer": true, "boolean": true, "null": true,
+}
+
+// parseJSONSchema decodes and checks a schema document.
+func parseJSONSchema(raw string) (*jsonSchema, error) {
+	var schema jsonSchema
+	if err := json.Unmarshal([]byte(raw), &schema); err != nil {
+		return nil, fmt.Errorf("invalid JSON schema: %w", err)
+	}
+	if err := schema.compile(""); err != nil {
+		return nil, fmt.Errorf("invalid JSON schema: %w", err)
+	}
+	return &schema, nil
+}
+
+// compile checks type names and patterns and resolves additionalProperties,
+// recursively. at is the location within the schema, for error messages.
+func (s *jsonSchema) compile(at string) error {
+	if len(s.unsupported) > 0 {
+		quoted := make([]string, len(s.unsupported))
+		for i, name := range s.unsupported {
+			quoted[i] = fmt.Sprintf("%q", name)
+		}
+		noun := "keyword"
+		if len(quoted) > 1 {
+			noun = "keywords"
+		}
+		return fmt.Errorf("%sunsupported %s %s: only type, enum, const, properties, required, additionalProperties, "+
+			"items, minItems, maxItems, minLength, maxLength, pattern, minimum and maximum are checked",
+			schemaLocation(at), noun, strings.Join(quoted, ", "))
+	}
+	for _, name := range s.Type {
+		if !jsonSchemaTypeNames[name] {
+			return fmt.Errorf("%sunknown type %q", schemaLocation(at), name)
+		}
+	}
+	if s.Pattern != "" {
+		re, err := regexp.Compile(s.Pattern)
+		if err != nil {
+			return fmt.Errorf("%sinvalid pattern: %w", schemaLocation(at), err)
+		}
+		s.pattern = re
+	}
+
+	if len(s.AdditionalProperties) > 0 {
+		var allowed bool
+		if err := json.Unmarshal(s.AdditionalProperties, &allowed); err == nil {
+			s.noAdditional = !allowed
+		} else {
+			var additional jsonSchema
+			if err := json.Unmarshal(s.AdditionalProperties, &additional); err != nil {
+				return fmt.Errorf("%s\"additionalProperties\" must be a boolean or a schema", schemaLocation(at))
+			}
+			if err := additional.compile(at + "/additionalProperties"); err != nil {
+				return err
+			}
+			s.additional = &additional
+		}
+	}
+
+	for name, property := range s.Properties {
+		if err := property.compile(at + "/properties/" + escapeJSONPointerToken(name)); err != nil {
+			return err
+		}
+	}
+	if s.Items != nil {
+		if err := s.Items.compile(at + "/items"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func schemaLocation(at string) string {
+	if at == "" {
+		return ""
+	}
+	return at + ": "
+}
+
+// validate returns one message per violation in value, each prefixed with
+// the JSON pointer of the offending element ("/" for the document itself).
+func (s *jsonSchema) validate(value interface{}) []string {
+	var violations []string
+	s.validateAt(value, "", &violations)
+	return violations
+}
+
+func (s *jsonSchema) validateAt(value interface{}, at string, violations *[]string) {
+	fail := func(format string, args ...interface{}) {
+		location := at
+		if location == "" {
+			location = "/"
+		}
+		*violations = append(*violations, fmt.Sprintf("%s: %s", location, fmt.Sprintf(format, args...)))
+	}
+
+	if len(s.Type) > 0 && !matchesAnyType(value, s.Type) {
+		fail("expected %s, got %s", strings.Join(s.Type, " or "), jsonTypeOf(value))
+		return
+	}
+	if s.Const != nil && !reflect.DeepEqual(value, *s.Const) {
+		fail("must equal %s", compactJSON(*s.Const))
+	}
+	if len(s.Enum) > 0 {
+		found := false
+		for _, allowed := range s.Enum {
+			if reflect.DeepEqual(value, allowed) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			fail("must be one of %s", compactJSON(s.Enum))
+		}
+	}
+
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for _, name := range s.Required {
+			if _, ok := v[name]; !ok {
+				fail("missing required property %q", name)
+			}
+		}
+		names := make([]string, 0, len(v))
+		for name := range v {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			child := at + "/" + escapeJSONPointerToken(name)
+			if property, ok := s.Properties[name]; ok {
+				property.validateAt(v[name], child, violations)
+			} else if s.noAdditional {
+				fail("property %q is not allowed", name)
+			} else if s.additional != nil {
+				s.additional.validateAt(v[name], child, violations)
+			}
+		}
+	case []interface{}:
+		if s.MinItems != nil && len(v) < *s.MinItems {
+			fail("must have at least %d items, got %d", *s.MinItems, len(v))
+		}
+		if s.MaxItems != nil && len(v) > *s.MaxItems {
+			fail("must have at most %d items, got %d", *s.MaxItems, len(v))
+		}
+		if s.Items != nil {
+			for i, item := range v {
+				s.Items.validateAt(item, fmt.Sprintf("%s/%d", at, i), violations)
+			}
+		}
+	case string:
+		length := len([]rune(v))
+		if s.MinLength != nil && length < *s.MinLength {
+			fail("must be at least %d characters, got %d", *s.MinLength, length)
+		}
+		if s.MaxLength != nil && length > *s.MaxLength {
+			fail("must be at most %d characters, got %d", *s.MaxLength, length)
+		}
+		if s.pattern != nil && !s.pattern.MatchString(v) {
+			fail("must match pattern %q", s.Pattern)
+		}
+	case float64:
+		if s.Minimum != nil && v < *s.Minimum {
+			fail("must be at least %v, got %v", *s.Minimum, v)
+		}
+		if s.Maximum != nil && v > *s.Maximum {
+			fail("must be at most %v, got %v", *s.Maximum, v)
+		}
+	}
+}
+
+func matchesAnyType(value interface{}, types []string) bool {
+	actual := jsonTypeOf(value)
+	for _, name := range types {
+		if name == actual || (name == "number" && actual == "integer") {
+			return true
+		}
+	}
+	return false
+}
+
+// jsonTypeOf returns the JSON Schema type name of a decoded JSON value.
+// Whole numbers report as "integer".
+func jsonTypeOf(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case float64:
+		if v == math.Trunc(v) {
+			return "integer"
+		}
+		return "number"
+	case string:
+		return "string"
+	case []interface{}:
+		return "array"
+	case map[string]interface{}:
+		return "object"
+	default:
+		return fmt.Sprintf("%T", value)
+	}
+}
+
+func compactJSON(value interface{}) string {
+	raw, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprintf("%v", value)
+	}
+	return string(raw)
+}
+
+// escapeJSONPointerToken escapes a property name for use in a JSON pointer.
+func escapeJSONPointerToken(token string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(token, "~", "~0"), "/", "~1")
+}
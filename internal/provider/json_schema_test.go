@@ -0,0 +1,81 @@
+package provider
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestParseJSONSchemaKeywords(t *testing.T) {
+	accepted := []string{
+		`{"type": "object", "properties": {"port": {"type": "integer", "minimum": 1, "maximum": 65535}}, "required": ["port"]}`,
+		`{"type": ["string", "null"], "minLength": 1, "maxLength": 8, "pattern": "^[a-z]+$"}`,
+		`{"type": "array", "items": {"enum": ["a", "b"]}, "minItems": 1, "maxItems": 2}`,
+		`{"additionalProperties": {"type": "string"}}`,
+		`{"additionalProperties": false}`,
+		`{"const": null}`,
+		`{"$schema": "https://json-schema.org/draft/2020-12/schema", "$id": "x", "$comment": "c", "title": "t",
+		  "description": "d", "default": 1, "examples": [1], "deprecated": false, "readOnly": false, "writeOnly": false}`,
+	}
+	for _, raw := range accepted {
+		if _, err := parseJSONSchema(raw); err != nil {
+			t.Errorf("parseJSONSchema(%s) = %s, want no error", raw, err)
+		}
+	}
+
+	rejected := map[string]string{
+		`{"type": "string", "format": "uri"}`:                      `"format"`,
+		`{"oneOf": [{"type": "string"}], "not": {"type": "null"}}`: `keywords "not", "oneOf"`,
+		`{"properties": {"a": {"exclusiveMinimum": 0}}}`:           `/properties/a: unsupported keyword "exclusiveMinimum"`,
+		`{"items": {"uniqueItems": true}}`:                         `/items: unsupported keyword "uniqueItems"`,
+		`{"additionalProperties": {"$ref": "#/$defs/x"}}`:          `/additionalProperties: unsupported keyword "$ref"`,
+		`{"type": "strng"}`:                                        `unknown type "strng"`,
+		`{"pattern": "("}`:                                         `invalid pattern`,
+		`{"additionalProperties": 1}`:                              `must be a boolean or a schema`,
+	}
+	for raw, want := range rejected {
+		_, err := parseJSONSchema(raw)
+		if err == nil {
+			t.Errorf("parseJSONSchema(%s) succeeded, want an error mentioning %s", raw, want)
+			continue
+		}
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("parseJSONSchema(%s) = %q, want it to mention %s", raw, err, want)
+		}
+	}
+}
+
+func TestJSONSchemaValidate(t *testing.T) {
+	schema, err := parseJSONSchema(`{
+		"type": "object",
+		"properties": {
+			"port": {"type": "integer", "minimum": 1},
+			"name": {"type": "string", "pattern": "^[a-z]+$"},
+			"unset": {"const": null}
+		},
+		"required": ["port"],
+		"additionalProperties": false
+	}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := map[string][]string{
+		`{"port": 8080, "name": "svc", "unset": null}`: nil,
+		`{"port": 0}`:                {"/port: must be at least 1, got 0"},
+		`{"name": "Svc"}`:            {"/: missing required property \"port\"", "/name: must match pattern \"^[a-z]+$\""},
+		`{"port": 1, "unset": "x"}`:  {"/unset: must equal null"},
+		`{"port": 1, "extra": true}`: {"/: property \"extra\" is not allowed"},
+		`{"port": "8080"}`:           {"/port: expected integer, got string"},
+	}
+	for raw, want := range tests {
+		var value interface{}
+		if err := json.Unmarshal([]byte(raw), &value); err != nil {
+			t.Fatal(err)
+		}
+		got := schema.validate(value)
+		if strings.Join(got, "\n") != strings.Join(want, "\n") {
+			t.Errorf("validate(%s) = %q, want %q", raw, got, want)
+		}
+	}
+}
@@ -0,0 +1,120 @@
+package provider
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+func TestNewRequestNamespaceMode(t *testing.T) {
+	tests := []struct {
+		mode, wantPath, wantHeader string
+	}{
+		{namespaceModeHeader, "/v1/app/data/svc", "team-a/sub"},
+		{"", "/v1/app/data/svc", "team-a/sub"},
+		{namespaceModePath, "/team-a/sub/v1/app/data/svc", ""},
+	}
+	for _, tt := range tests {
+		client := &VaultClient{
+			Address:       "https://vault.example.com",
+			Namespace:     "team-a/sub",
+			NamespaceMode: tt.mode,
+			cache:         &clientCache{},
+		}
+		req, err := client.newRequest(context.Background(), "GET", "app/data/svc", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if req.URL.Path != tt.wantPath {
+			t.Errorf("mode %q: path = %q, want %q", tt.mode, req.URL.Path, tt.wantPath)
+		}
+		if got := req.Header.Get("X-Vault-Namespace"); got != tt.wantHeader {
+			t.Errorf("mode %q: X-Vault-Namespace = %q, want %q", tt.mode, got, tt.wantHeader)
+		}
+	}
+}
+
+func TestNewRequestWithoutNamespace(t *testing.T) {
+	client := &VaultClient{Address: "https://vault.example.com", NamespaceMode: namespaceModePath, cache: &clientCache{}}
+	req, err := client.newRequest(context.Background(), "GET", "app/data/svc", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if req.URL.Path != "/v1/app/data/svc" || req.Header.Get("X-Vault-Namespace") != "" {
+		t.Errorf("unexpected request %s with namespace header %q", req.URL.Path, req.Header.Get("X-Vault-Namespace"))
+	}
+}
+
+func TestAuthenticateAppRoleNamespacePath(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/team-a/v1/auth/approle/login" {
+			t.Errorf("login sent to %s", r.URL.Path)
+		}
+		if got := r.Header.Get("X-Vault-Namespace"); got != "" {
+			t.Errorf("X-Vault-Namespace = %q, want none in path mode", got)
+		}
+		writeJSON(t, w, map[string]interface{}{"auth": map[string]interface{}{"client_token": "s.issued"}})
+	}))
+	defer srv.Close()
+
+	login, err := authenticateAppRole(srv.Client(), 0, retryBackoff{}, srv.URL, "team-a", namespaceModePath, "role", "secret",
+		defaultLoginTokenPath, 0, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if login.Token != "s.issued" {
+		t.Errorf("token = %q, want %q", login.Token, "s.issued")
+	}
+}
+
+func TestConfiguredNamespace(t *testing.T) {
+	t.Setenv("VAULT_NAMESPACE", "/from-env/")
+
+	if got := configuredNamespace(types.StringNull()); got != "from-env" {
+		t.Errorf("unset attribute: namespace = %q, want VAULT_NAMESPACE %q", got, "from-env")
+	}
+	if got := configuredNamespace(types.StringValue("team-a")); got != "team-a" {
+		t.Errorf("set attribute: namespace = %q, want %q", got, "team-a")
+	}
+	if got := configuredNamespace(types.StringValue("")); got != "" {
+		t.Errorf("empty attribute: namespace = %q, want the root namespace", got)
+	}
+
+	t.Setenv("VAULT_NAMESPACE", "")
+	if got := configuredNamespace(types.StringNull()); got != "" {
+		t.Errorf("nothing set: namespace = %q, want the root namespace", got)
+	}
+}
+
+func TestNamespaceHeaderOnEveryRequest(t *testing.T) {
+	store := newKVStore(t, "app")
+	var requests int
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if got := r.Header.Get("X-Vault-Namespace"); got != "team-a" {
+			t.Errorf("%s %s: X-Vault-Namespace = %q, want %q", r.Method, r.URL.Path, got, "team-a")
+		}
+		store.ServeHTTP(w, r)
+	})
+	client.Namespace = "team-a"
+	client.VerifyWrite = true
+	client.DefaultCustomMetadata = map[string]string{"team": "a"}
+	ctx := context.Background()
+
+	if _, err := client.writeSecret(ctx, "app", "svc", map[string]string{"A": "1"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.readSecret(ctx, "app", "svc"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.readMetadata(ctx, "app", "svc"); err != nil {
+		t.Fatal(err)
+	}
+	// Write, verification read, metadata read and update, read, metadata read.
+	if requests != 6 {
+		t.Errorf("expected 6 requests, got %d", requests)
+	}
+}
@@ -0,0 +1,87 @@
+package provider
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	tfpath "github.com/hashicorp/terraform-plugin-framework/path"
+)
+
+const (
+	pathNormalizationNormalize = "normalize"
+	pathNormalizationError     = "error"
+)
+
+// cleanSecretPath collapses duplicate slashes and resolves "." and ".."
+// segments in p, a mount or a path within one, and drops leading and
+// trailing slashes. The result may not be empty, and ".." may not climb
+// above p itself, so a path can never reach into another mount or a
+// different API endpoint.
+func cleanSecretPath(p string) (string, error) {
+	var segments []string
+	for _, segment := range strings.Split(p, "/") {
+		switch segment {
+		case "", ".":
+		case "..":
+			if len(segments) == 0 {
+				return "", fmt.Errorf("%q climbs above its root with '..'", p)
+			}
+			segments = segments[:len(segments)-1]
+		default:
+			segments = append(segments, segment)
+		}
+	}
+	if len(segments) == 0 {
+		return "", fmt.Errorf("%q is empty once slashes and '.' segments are removed", p)
+	}
+	return strings.Join(segments, "/"), nil
+}
+
+// cleanSecretPaths cleans mount and path for use in API paths following the
+// path_normalization mode, adding an attribute error for each that cannot be
+// cleaned, or that is not clean already when mode is pathNormalizationError.
+func cleanSecretPaths(mount, path, mode string, diags *diag.Diagnostics) (string, string, bool) {
+	if !validPathNormalization(mode, diags) {
+		return "", "", false
+	}
+	cleanMount, mountOK := cleanSecretPathAttr("mount", "Mount", mount, mode, diags)
+	cleanPath, pathOK := cleanSecretPathAttr("path", "Path", path, mode, diags)
+	return cleanMount, cleanPath, mountOK && pathOK
+}
+
+// validPathNormalization reports whether mode is a path_normalization mode,
+// adding an attribute error when it is not.
+func validPathNormalization(mode string, diags *diag.Diagnostics) bool {
+	switch mode {
+	case "", pathNormalizationNormalize, pathNormalizationError:
+		return true
+	}
+	diags.AddAttributeError(
+		tfpath.Root("path_normalization"),
+		"Invalid Path Normalization",
+		fmt.Sprintf("Must be %q or %q, got %q.", pathNormalizationNormalize, pathNormalizationError, mode),
+	)
+	return false
+}
+
+// cleanSecretPathAttr cleans value, the attribute attr, following the
+// path_normalization mode, adding an attribute error when it cannot be
+// cleaned or, with pathNormalizationError, is not clean already.
+func cleanSecretPathAttr(attr, label, value, mode string, diags *diag.Diagnostics) (string, bool) {
+	clean, err := cleanSecretPath(value)
+	if err != nil {
+		diags.AddAttributeError(tfpath.Root(attr), "Invalid "+label, err.Error())
+		return "", false
+	}
+	if mode == pathNormalizationError && clean != value {
+		diags.AddAttributeError(
+			tfpath.Root(attr),
+			"Path Not Normalized",
+			fmt.Sprintf("'%s' is %q, which has duplicate, leading or trailing slashes or '.' or '..' segments. "+
+				"Use %q, or set path_normalization = %q.", attr, value, clean, pathNormalizationNormalize),
+		)
+		return "", false
+	}
+	return clean, true
+}
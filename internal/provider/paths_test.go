@@ -0,0 +1,61 @@
+package provider
+
+import (
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+)
+
+func TestCleanSecretPath(t *testing.T) {
+	for _, tc := range []struct {
+		in, want string
+	}{
+		{"my-service/test", "my-service/test"},
+		{"my//service", "my/service"},
+		{"my/./service", "my/service"},
+		{"/my/service/", "my/service"},
+		{"my/other/../service", "my/service"},
+		{"./my/service", "my/service"},
+	} {
+		got, err := cleanSecretPath(tc.in)
+		if err != nil {
+			t.Errorf("cleanSecretPath(%q): unexpected error %s", tc.in, err)
+			continue
+		}
+		if got != tc.want {
+			t.Errorf("cleanSecretPath(%q) = %q, want %q", tc.in, got, tc.want)
+		}
+	}
+
+	for _, in := range []string{"", "/", "./.", "..", "my/../..", "../other-mount/secret"} {
+		if got, err := cleanSecretPath(in); err == nil {
+			t.Errorf("cleanSecretPath(%q) = %q, want an error", in, got)
+		}
+	}
+}
+
+func TestCleanSecretPathsModes(t *testing.T) {
+	var diags diag.Diagnostics
+	mount, path, ok := cleanSecretPaths("app/", "my//service", pathNormalizationNormalize, &diags)
+	if !ok || diags.HasError() {
+		t.Fatalf("normalize: unexpected errors %v", diags)
+	}
+	if mount != "app" || path != "my/service" {
+		t.Errorf("normalize: got %q, %q", mount, path)
+	}
+
+	diags = nil
+	if _, _, ok := cleanSecretPaths("app", "my//service", pathNormalizationError, &diags); ok || diags.ErrorsCount() != 1 {
+		t.Errorf("error mode: expected one error, got %v", diags)
+	}
+
+	diags = nil
+	if _, _, ok := cleanSecretPaths("app", "my/service", pathNormalizationError, &diags); !ok || diags.HasError() {
+		t.Errorf("error mode: clean paths should pass, got %v", diags)
+	}
+
+	diags = nil
+	if _, _, ok := cleanSecretPaths("app", "my/service", "fix", &diags); ok || !diags.HasError() {
+		t.Errorf("unknown mode should be rejected, got %v", diags)
+	}
+}
@@ -3,10 +3,16 @@ package provider
 import (
 	"bytes"
 	"context"
+	"crypto/tls"
+	"crypto/x509"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
+	"os"
+	"regexp"
+	"strings"
 	"time"
 
 	"github.com/hashicorp/terraform-plugin-framework/datasource"
@@ -14,24 +20,79 @@ import (
 	"github.com/hashicorp/terraform-plugin-framework/provider/schema"
 	"github.com/hashicorp/terraform-plugin-framework/resource"
 	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
 )
 
 var _ provider.Provider = &VaultPatchProvider{}
 
 type VaultPatchProvider struct {
 	version string
+
+	// transport, when set, carries every request the provider sends,
+	// including login, instead of the one built from the connection
+	// settings. It is a seam for tests and interception and is never set by
+	// New.
+	transport http.RoundTripper
 }
 
 type VaultPatchProviderModel struct {
 	Address  types.String `tfsdk:"address"`
 	RoleID   types.String `tfsdk:"role_id"`
 	SecretID types.String `tfsdk:"secret_id"`
-}
+	Token    types.String `tfsdk:"token"`
+
+	Namespace     types.String `tfsdk:"namespace"`
+	NamespaceMode types.String `tfsdk:"namespace_mode"`
+
+	ReadCache    types.Bool `tfsdk:"read_cache"`
+	TokenInQuery types.Bool `tfsdk:"token_in_query"`
+
+	TokenHeaderStyle  types.String `tfsdk:"token_header_style"`
+	OperationLogLevel types.String `tfsdk:"operation_log_level"`
+
+	RequestJSONFormat      types.String `tfsdk:"request_json_format"`
+	RequestTrailingNewline types.Bool   `tfsdk:"request_trailing_newline"`
+
+	ConditionalReads types.Bool `tfsdk:"conditional_reads"`
+	DryRun           types.Bool `tfsdk:"dry_run"`
+	ReadOnly         types.Bool `tfsdk:"read_only"`
+	MaskLogValues    types.Bool `tfsdk:"mask_log_values"`
+	VerifyWrite      types.Bool `tfsdk:"verify_write"`
+
+	ContentAddressedWrites types.Bool `tfsdk:"content_addressed_writes"`
+
+	LoginTokenPath types.String `tfsdk:"login_token_path"`
+	LoginTTL       types.String `tfsdk:"login_ttl"`
+	TokenPolicies  types.List   `tfsdk:"token_policies"`
+
+	MaxRequestBytes types.Int64 `tfsdk:"max_request_bytes"`
+	MaxSecretBytes  types.Int64 `tfsdk:"max_secret_bytes"`
+
+	AllowSchemeDowngrade types.Bool `tfsdk:"allow_scheme_downgrade"`
+
+	TokenCacheFile types.String `tfsdk:"token_cache_file"`
+	Headers        types.Map    `tfsdk:"headers"`
+	DNSRetries     types.Int64  `tfsdk:"dns_retries"`
+	MaxRetries     types.Int64  `tfsdk:"max_retries"`
+
+	RetryBaseDelayMs types.Int64  `tfsdk:"retry_base_delay_ms"`
+	RetryMaxDelayMs  types.Int64  `tfsdk:"retry_max_delay_ms"`
+	RetryJitter      types.String `tfsdk:"retry_jitter"`
+
+	DefaultCustomMetadata types.Map `tfsdk:"default_custom_metadata"`
+
+	AllowValueCommands types.Bool `tfsdk:"allow_value_commands"`
+
+	ResponseDataPath types.String `tfsdk:"response_data_path"`
+
+	WaitForUnsealSeconds    types.Int64 `tfsdk:"wait_for_unseal_seconds"`
+	ControlGroupWaitSeconds types.Int64 `tfsdk:"control_group_wait_seconds"`
+
+	IdleConnTimeoutSeconds types.Int64 `tfsdk:"idle_conn_timeout_seconds"`
+	DisableKeepAlives      types.Bool  `tfsdk:"disable_keep_alives"`
 
-type VaultClient struct {
-	Address    string
-	Token      string
-	HTTPClient *http.Client
+	CACertFile types.String `tfsdk:"ca_cert_file"`
+	CACertPEM  types.String `tfsdk:"ca_cert_pem"`
 }
 
 func New(version string) func() provider.Provider {
@@ -58,15 +119,223 @@ func (p *VaultPatchProvider) Schema(_ context.Context, _ provider.SchemaRequest,
 				Sensitive:   false,
 			},
 			"role_id": schema.StringAttribute{
-				Description: "The AppRole Role ID for authenticating with Vault.",
-				Required:    true,
+				Description: "The AppRole Role ID for authenticating with Vault. Required unless 'token' is set.",
+				Optional:    true,
 				Sensitive:   true,
 			},
 			"secret_id": schema.StringAttribute{
-				Description: "The AppRole Secret ID for authenticating with Vault.",
-				Required:    true,
+				Description: "The AppRole Secret ID for authenticating with Vault. Required unless 'token' is set.",
+				Optional:    true,
 				Sensitive:   true,
 			},
+			"token": schema.StringAttribute{
+				Description: "A Vault token to use as is instead of logging in with AppRole, such as a short-lived " +
+					"token from a CI pipeline or 'vault login'. Set either 'token' or 'role_id' and 'secret_id'. " +
+					"Accepted for any address.",
+				Optional:  true,
+				Sensitive: true,
+			},
+			"read_cache": schema.BoolAttribute{
+				Description: "Share secret reads between resources targeting the same path within a single run. " +
+					"A path is re-read after this provider writes to it. Defaults to false.",
+				Optional: true,
+			},
+			"token_in_query": schema.BoolAttribute{
+				Description: "Send the Vault token as a 'token' query parameter instead of the X-Vault-Token header. " +
+					"Only for gateways that strip the header; tokens in URLs may end up in access logs. Defaults to false.",
+				Optional: true,
+			},
+			"request_json_format": schema.StringAttribute{
+				Description: "How JSON request bodies are encoded: 'compact' or 'indent' (two spaces per level), for " +
+					"gateways or WAFs that reject minified JSON. Defaults to 'compact'.",
+				Optional: true,
+			},
+			"request_trailing_newline": schema.BoolAttribute{
+				Description: "End JSON request bodies with a newline. Defaults to false.",
+				Optional:    true,
+			},
+			"token_header_style": schema.StringAttribute{
+				Description: "How the Vault token is sent: 'x-vault-token' (the X-Vault-Token header) or 'bearer' " +
+					"(an 'Authorization: Bearer' header, for proxies that expect it). Defaults to 'x-vault-token'.",
+				Optional: true,
+			},
+			"conditional_reads": schema.BoolAttribute{
+				Description: "Check a path's metadata version before reading it and reuse the previously read data " +
+					"when the version is unchanged. Requires read on the metadata endpoint; falls back to full reads otherwise. " +
+					"Defaults to false.",
+				Optional: true,
+			},
+			"dry_run": schema.BoolAttribute{
+				Description: "Run the full read/merge logic on apply but skip every write to Vault, logging the intended " +
+					"change (key names only) instead. State records the intended result. Defaults to false.",
+				Optional: true,
+			},
+			"read_only": schema.BoolAttribute{
+				Description: "Fail every resource create, update and delete with an error before anything is sent to " +
+					"Vault, for pipelines that should only inspect. Data sources and refresh keep working. Defaults to false.",
+				Optional: true,
+			},
+			"mask_log_values": schema.BoolAttribute{
+				Description: "Scrub managed values from all provider log output as a safeguard. Defaults to true.",
+				Optional:    true,
+			},
+			"operation_log_level": schema.StringAttribute{
+				Description: "Level of the messages resources log for each create, read, update and delete (mount, " +
+					"path and key names): 'info', 'debug' or 'off'. Warnings and errors are unaffected, and values are " +
+					"never logged. Defaults to 'info'.",
+				Optional: true,
+			},
+			"verify_write": schema.BoolAttribute{
+				Description: "Read every write back (at the version it created) and fail if the stored data differs " +
+					"from what was sent. Costs one extra read per write. Defaults to false.",
+				Optional: true,
+			},
+			"content_addressed_writes": schema.BoolAttribute{
+				Description: "Record a hash of the written content in the secret's custom_metadata and skip writes whose " +
+					"content matches the current version, even across runs. KV v2 only; needs metadata read/write access. " +
+					"Defaults to false.",
+				Optional: true,
+			},
+			"login_token_path": schema.StringAttribute{
+				Description: "Dotted JSON path to the client token in the login response, " +
+					"for auth backends that nest it differently. Defaults to 'auth.client_token'.",
+				Optional: true,
+			},
+			"login_ttl": schema.StringAttribute{
+				Description: "Token TTL to request at login as a Go duration (e.g., '2h'), so long applies start with " +
+					"a long-enough token. Vault clamps it to the role's token_max_ttl; the granted TTL is logged and a " +
+					"warning is raised when it is shorter than requested. Defaults to the role's token_ttl.",
+				Optional: true,
+			},
+			"token_policies": schema.ListAttribute{
+				Description: "Policies to request for the login token, to scope it below everything the role grants. " +
+					"The role must allow requesting them; a warning is raised when the token Vault returns carries " +
+					"other policies (besides 'default'). Defaults to the role's token_policies.",
+				Optional:    true,
+				ElementType: types.StringType,
+			},
+			"max_request_bytes": schema.Int64Attribute{
+				Description: "Fail a write before sending it when its encoded payload is larger than this many bytes, " +
+					"naming the keys that push it over. Set it to the server's max_request_size; 0 disables the check. " +
+					"Defaults to 33554432 (32 MiB, Vault's default).",
+				Optional: true,
+			},
+			"max_secret_bytes": schema.Int64Attribute{
+				Description: "Fail a write before sending it when the encoded secret data is larger than this many bytes, " +
+					"naming the keys that push it over. Set it below the storage backend's max entry size " +
+					"(e.g., 1 MiB for integrated storage). Defaults to 0 (no check).",
+				Optional: true,
+			},
+			"allow_scheme_downgrade": schema.BoolAttribute{
+				Description: "Follow redirects from https to http. By default such redirects, usually caused by a Vault " +
+					"api_addr or redirect_addr configured with http, fail the request so the token is never sent in " +
+					"clear text. Applies to login and KV requests. Defaults to false.",
+				Optional: true,
+			},
+			"token_cache_file": schema.StringAttribute{
+				Description: "File in which to cache the login token between runs, reused while Vault still accepts it " +
+					"and it has at least 5 minutes left. The file is created with mode 0600 and ignored if other users " +
+					"can access it. Anyone who can read the file can use the token, so only enable this on " +
+					"single-user workstations. Defaults to no caching.",
+				Optional: true,
+			},
+			"namespace": schema.StringAttribute{
+				Description: "The Vault Enterprise namespace (e.g., 'admin/team-a') to log in to and send every request " +
+					"to, as the X-Vault-Namespace header. Resources can override it. Defaults to the VAULT_NAMESPACE " +
+					"environment variable, else the root namespace.",
+				Optional: true,
+			},
+			"namespace_mode": schema.StringAttribute{
+				Description: "How the namespace is sent: 'header' (X-Vault-Namespace) or 'path', as a URL prefix " +
+					"('<address>/<namespace>/v1/...') for gateways that route on the path. Applies to login and every " +
+					"request, including resource namespace overrides. Defaults to 'header'.",
+				Optional: true,
+			},
+			"headers": schema.MapAttribute{
+				Description: "HTTP headers added to every KV and metadata request, e.g. for gateway routing. " +
+					"X-Vault-Token, X-Vault-Namespace, Authorization and Content-Type cannot be set.",
+				Optional:    true,
+				ElementType: types.StringType,
+			},
+			"default_custom_metadata": schema.MapAttribute{
+				Description: "custom_metadata entries added to every KV v2 secret a resource writes, e.g. cost center " +
+					"or team. Entries the secret already has keep their value, so defaults never overwrite metadata " +
+					"set by anyone else, and changing a default does not rewrite secrets that already carry the key.",
+				Optional:    true,
+				ElementType: types.StringType,
+			},
+			"allow_value_commands": schema.BoolAttribute{
+				Description: "Let resources pipe values through the local programs named in their 'value_command' and " +
+					"'value_read_command'. Those programs run with the provider's environment and see every managed " +
+					"value in plaintext, so only enable this for trusted configurations. Defaults to false.",
+				Optional: true,
+			},
+			"dns_retries": schema.Int64Attribute{
+				Description: "How many times a request, including the AppRole login, is retried, with the 'retry_*' " +
+					"backoff, when the Vault host name fails to resolve. 0 disables the retries. 'wait_for_unseal_seconds' " +
+					"polling retries any failure until it times out instead. Defaults to 3.",
+				Optional: true,
+			},
+			"max_retries": schema.Int64Attribute{
+				Description: "How many times a read is retried, with the 'retry_*' backoff, when Vault answers 412 " +
+					"because a performance standby has not caught up with a recent write yet. " +
+					"0 disables the retries. Defaults to 3.",
+				Optional: true,
+			},
+			"retry_base_delay_ms": schema.Int64Attribute{
+				Description: "Milliseconds before the first retry of any kind; the wait doubles with every further " +
+					"attempt up to 'retry_max_delay_ms'. Defaults to 100.",
+				Optional: true,
+			},
+			"retry_max_delay_ms": schema.Int64Attribute{
+				Description: "Upper bound in milliseconds of the wait between retries. Must not be below " +
+					"'retry_base_delay_ms'. Defaults to 5000.",
+				Optional: true,
+			},
+			"retry_jitter": schema.StringAttribute{
+				Description: "How retry waits are randomized: 'full' (anywhere up to the computed wait), 'equal' " +
+					"(at least half of it) or 'none'. Defaults to 'full'.",
+				Optional: true,
+			},
+			"response_data_path": schema.StringAttribute{
+				Description: "Dotted JSON path to the secret data object in read responses, for gateways that wrap or " +
+					"reshape Vault responses. Defaults to the KV shape: 'data.data' on KV v2, 'data' on KV v1.",
+				Optional: true,
+			},
+			"wait_for_unseal_seconds": schema.Int64Attribute{
+				Description: "When Vault is sealed or unreachable at startup, poll sys/health for up to this many seconds " +
+					"until it is unsealed before logging in, e.g. for automation that runs right after a restart. " +
+					"Defaults to 0 (no waiting).",
+				Optional: true,
+			},
+			"control_group_wait_seconds": schema.Int64Attribute{
+				Description: "When a read or write is held by a Vault Enterprise Control Group, wait up to this many " +
+					"seconds for an approver to authorize it before failing. Defaults to 0: fail at once with the request " +
+					"accessor approvers need.",
+				Optional: true,
+			},
+			"idle_conn_timeout_seconds": schema.Int64Attribute{
+				Description: "How long an idle keep-alive connection to Vault is kept for reuse. Lower it below the idle " +
+					"timeout of any load balancer in front of Vault, so the provider never reuses a connection the load " +
+					"balancer has already dropped. 0 keeps idle connections indefinitely. Defaults to 90 (Go's default).",
+				Optional: true,
+			},
+			"disable_keep_alives": schema.BoolAttribute{
+				Description: "Open a new connection, with a new TLS handshake, for every request instead of reusing " +
+					"idle ones. Slower, but immune to dropped idle connections. Defaults to false.",
+				Optional: true,
+			},
+			"ca_cert_file": schema.StringAttribute{
+				Description: "Path to a PEM file with the CA certificates that sign the Vault server certificate, for " +
+					"Vault behind an internal CA. They replace the system roots for every request, including login. " +
+					"Conflicts with 'ca_cert_pem'.",
+				Optional: true,
+			},
+			"ca_cert_pem": schema.StringAttribute{
+				Description: "PEM-encoded CA certificates that sign the Vault server certificate, as 'ca_cert_file' " +
+					"but inline. Conflicts with 'ca_cert_file'.",
+				Optional: true,
+			},
 		},
 	}
 }
@@ -83,34 +352,457 @@ func (p *VaultPatchProvider) Configure(ctx context.Context, req provider.Configu
 		resp.Diagnostics.AddError("Missing Vault Address", "The 'address' attribute must be set.")
 		return
 	}
-	if config.RoleID.IsUnknown() || config.RoleID.IsNull() {
-		resp.Diagnostics.AddError("Missing Role ID", "The 'role_id' attribute must be set.")
+	if config.Token.IsUnknown() {
+		resp.Diagnostics.AddError("Unknown Vault Token", "The 'token' attribute must be known when the provider is configured.")
 		return
 	}
-	if config.SecretID.IsUnknown() || config.SecretID.IsNull() {
-		resp.Diagnostics.AddError("Missing Secret ID", "The 'secret_id' attribute must be set.")
-		return
+	// Accepted for any address.
+	staticToken := config.Token.ValueString()
+	if staticToken == "" {
+		if config.RoleID.IsNull() && config.SecretID.IsNull() {
+			resp.Diagnostics.AddError(
+				"Missing Vault Credentials",
+				"Exactly one auth method is required: set either 'token' or both 'role_id' and 'secret_id'.",
+			)
+			return
+		}
+		if config.RoleID.IsUnknown() || config.RoleID.IsNull() {
+			resp.Diagnostics.AddError("Missing Role ID", "The 'role_id' attribute must be set.")
+			return
+		}
+		if config.SecretID.IsUnknown() || config.SecretID.IsNull() {
+			resp.Diagnostics.AddError("Missing Secret ID", "The 'secret_id' attribute must be set.")
+			return
+		}
 	}
 
-	address := config.Address.ValueString()
+	address := normalizeAddress(config.Address.ValueString())
 	roleID := config.RoleID.ValueString()
 	secretID := config.SecretID.ValueString()
 
-	token, err := authenticateAppRole(address, roleID, secretID)
-	if err != nil {
+	if staticToken != "" && (!config.RoleID.IsNull() || !config.SecretID.IsNull()) {
+		resp.Diagnostics.AddError(
+			"Conflicting Credentials",
+			"Exactly one auth method is allowed: set either 'token' or 'role_id' and 'secret_id', not both.",
+		)
+		return
+	}
+
+	tokenPath := defaultLoginTokenPath
+	if !config.LoginTokenPath.IsNull() && !config.LoginTokenPath.IsUnknown() {
+		tokenPath = config.LoginTokenPath.ValueString()
+	}
+	if !validJSONPath(tokenPath) {
+		resp.Diagnostics.AddError(
+			"Invalid Login Token Path",
+			fmt.Sprintf("'login_token_path' must be a dotted path of non-empty segments (e.g., 'auth.client_token'), got %q.", tokenPath),
+		)
+		return
+	}
+
+	tokenHeaderStyle := tokenHeaderVault
+	if !config.TokenHeaderStyle.IsNull() && !config.TokenHeaderStyle.IsUnknown() {
+		tokenHeaderStyle = config.TokenHeaderStyle.ValueString()
+	}
+	if tokenHeaderStyle != tokenHeaderVault && tokenHeaderStyle != tokenHeaderBearer {
+		resp.Diagnostics.AddError(
+			"Invalid Token Header Style",
+			fmt.Sprintf("'token_header_style' must be %q or %q, got %q.", tokenHeaderVault, tokenHeaderBearer, tokenHeaderStyle),
+		)
+		return
+	}
+
+	requestJSONFormat := jsonFormatCompact
+	if !config.RequestJSONFormat.IsNull() && !config.RequestJSONFormat.IsUnknown() {
+		requestJSONFormat = config.RequestJSONFormat.ValueString()
+	}
+	if requestJSONFormat != jsonFormatCompact && requestJSONFormat != jsonFormatIndent {
+		resp.Diagnostics.AddError(
+			"Invalid Request JSON Format",
+			fmt.Sprintf("'request_json_format' must be %q or %q, got %q.", jsonFormatCompact, jsonFormatIndent, requestJSONFormat),
+		)
+		return
+	}
+
+	operationLogLevel := operationLogInfo
+	if !config.OperationLogLevel.IsNull() && !config.OperationLogLevel.IsUnknown() {
+		operationLogLevel = config.OperationLogLevel.ValueString()
+	}
+	switch operationLogLevel {
+	case operationLogInfo, operationLogDebug, operationLogOff:
+	default:
+		resp.Diagnostics.AddError(
+			"Invalid Operation Log Level",
+			fmt.Sprintf("'operation_log_level' must be %q, %q or %q, got %q.",
+				operationLogInfo, operationLogDebug, operationLogOff, operationLogLevel),
+		)
+		return
+	}
+
+	responseDataPath := config.ResponseDataPath.ValueString()
+	if responseDataPath != "" && !validJSONPath(responseDataPath) {
+		resp.Diagnostics.AddError(
+			"Invalid Response Data Path",
+			fmt.Sprintf("'response_data_path' must be a dotted path of non-empty segments (e.g., 'data.data'), got %q.", responseDataPath),
+		)
+		return
+	}
+
+	var loginTTL time.Duration
+	if !config.LoginTTL.IsNull() && !config.LoginTTL.IsUnknown() {
+		ttl, err := time.ParseDuration(config.LoginTTL.ValueString())
+		if err != nil || ttl <= 0 {
+			resp.Diagnostics.AddError(
+				"Invalid Login TTL",
+				fmt.Sprintf("'login_ttl' must be a positive duration (e.g., '2h'), got %q.", config.LoginTTL.ValueString()),
+			)
+			return
+		}
+		loginTTL = ttl
+	}
+
+	var tokenPolicies []string
+	if !config.TokenPolicies.IsNull() && !config.TokenPolicies.IsUnknown() {
+		resp.Diagnostics.Append(config.TokenPolicies.ElementsAs(ctx, &tokenPolicies, false)...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+		seen := make(map[string]bool, len(tokenPolicies))
+		for _, policy := range tokenPolicies {
+			if policy == "" || seen[policy] {
+				resp.Diagnostics.AddError(
+					"Invalid Token Policies",
+					fmt.Sprintf("'token_policies' entries must be non-empty and unique, got %q.", policy),
+				)
+				return
+			}
+			seen[policy] = true
+		}
+	}
+
+	namespace := configuredNamespace(config.Namespace)
+
+	namespaceMode := namespaceModeHeader
+	if !config.NamespaceMode.IsNull() && !config.NamespaceMode.IsUnknown() {
+		namespaceMode = config.NamespaceMode.ValueString()
+	}
+	if namespaceMode != namespaceModeHeader && namespaceMode != namespaceModePath {
+		resp.Diagnostics.AddError(
+			"Invalid Namespace Mode",
+			fmt.Sprintf("'namespace_mode' must be %q or %q, got %q.", namespaceModeHeader, namespaceModePath, namespaceMode),
+		)
+		return
+	}
+
+	// A cached token is only reused for the same role, requested policies
+	// and namespace, so changing any of them forces a fresh login.
+	cacheIdentity := roleID
+	if len(tokenPolicies) > 0 {
+		cacheIdentity += "\x00" + strings.Join(tokenPolicies, ",")
+	}
+	if namespace != "" {
+		cacheIdentity += "\x00" + namespace
+	}
+
+	maxRequestBytes := defaultMaxRequestBytes
+	if !config.MaxRequestBytes.IsNull() && !config.MaxRequestBytes.IsUnknown() {
+		if config.MaxRequestBytes.ValueInt64() < 0 {
+			resp.Diagnostics.AddError(
+				"Invalid Max Request Bytes",
+				fmt.Sprintf("'max_request_bytes' must not be negative, got %d.", config.MaxRequestBytes.ValueInt64()),
+			)
+			return
+		}
+		maxRequestBytes = int(config.MaxRequestBytes.ValueInt64())
+	}
+
+	if config.MaxSecretBytes.ValueInt64() < 0 {
+		resp.Diagnostics.AddError(
+			"Invalid Max Secret Bytes",
+			fmt.Sprintf("'max_secret_bytes' must not be negative, got %d.", config.MaxSecretBytes.ValueInt64()),
+		)
+		return
+	}
+
+	dnsRetries := defaultDNSRetries
+	if !config.DNSRetries.IsNull() && !config.DNSRetries.IsUnknown() {
+		if config.DNSRetries.ValueInt64() < 0 {
+			resp.Diagnostics.AddError(
+				"Invalid DNS Retries",
+				fmt.Sprintf("'dns_retries' must not be negative, got %d.", config.DNSRetries.ValueInt64()),
+			)
+			return
+		}
+		dnsRetries = int(config.DNSRetries.ValueInt64())
+	}
+
+	maxRetries := defaultMaxRetries
+	if !config.MaxRetries.IsNull() && !config.MaxRetries.IsUnknown() {
+		if config.MaxRetries.ValueInt64() < 0 {
+			resp.Diagnostics.AddError(
+				"Invalid Max Retries",
+				fmt.Sprintf("'max_retries' must not be negative, got %d.", config.MaxRetries.ValueInt64()),
+			)
+			return
+		}
+		maxRetries = int(config.MaxRetries.ValueInt64())
+	}
+
+	backoff := defaultRetryBackoff
+	if !config.RetryBaseDelayMs.IsNull() && !config.RetryBaseDelayMs.IsUnknown() {
+		if config.RetryBaseDelayMs.ValueInt64() < 1 {
+			resp.Diagnostics.AddError(
+				"Invalid Retry Base Delay",
+				fmt.Sprintf("'retry_base_delay_ms' must be at least 1, got %d.", config.RetryBaseDelayMs.ValueInt64()),
+			)
+			return
+		}
+		backoff.Base = time.Duration(config.RetryBaseDelayMs.ValueInt64()) * time.Millisecond
+	}
+	if !config.RetryMaxDelayMs.IsNull() && !config.RetryMaxDelayMs.IsUnknown() {
+		backoff.Max = time.Duration(config.RetryMaxDelayMs.ValueInt64()) * time.Millisecond
+	}
+	if backoff.Max < backoff.Base {
+		resp.Diagnostics.AddError(
+			"Invalid Retry Delays",
+			fmt.Sprintf("'retry_max_delay_ms' (%d) must not be below 'retry_base_delay_ms' (%d).",
+				backoff.Max.Milliseconds(), backoff.Base.Milliseconds()),
+		)
+		return
+	}
+	if !config.RetryJitter.IsNull() && !config.RetryJitter.IsUnknown() {
+		backoff.Jitter = config.RetryJitter.ValueString()
+	}
+	switch backoff.Jitter {
+	case retryJitterFull, retryJitterEqual, retryJitterNone:
+	default:
+		resp.Diagnostics.AddError(
+			"Invalid Retry Jitter",
+			fmt.Sprintf("'retry_jitter' must be %q, %q or %q, got %q.", retryJitterFull, retryJitterEqual, retryJitterNone, backoff.Jitter),
+		)
+		return
+	}
+
+	headers := make(map[string]string)
+	resp.Diagnostics.Append(config.Headers.ElementsAs(ctx, &headers, false)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	for name := range headers {
+		if isReservedHeader(name) {
+			resp.Diagnostics.AddError(
+				"Reserved Header",
+				fmt.Sprintf("'headers' cannot set %q; it is managed by the provider.", name),
+			)
+			return
+		}
+	}
+
+	defaultCustomMetadata := make(map[string]string)
+	resp.Diagnostics.Append(config.DefaultCustomMetadata.ElementsAs(ctx, &defaultCustomMetadata, false)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	for key := range defaultCustomMetadata {
+		if key == "" || key == contentHashMetadataKey {
+			resp.Diagnostics.AddError(
+				"Invalid Custom Metadata Key",
+				fmt.Sprintf("'default_custom_metadata' cannot set %q.", key),
+			)
+			return
+		}
+	}
+
+	if staticToken == "" && roleID == secretID {
 		resp.Diagnostics.AddError(
-			"Vault Authentication Failed",
-			fmt.Sprintf("Could not authenticate with Vault at %s: %s", address, err),
+			"Role ID and Secret ID Are Identical",
+			"'role_id' and 'secret_id' have the same value. One of them was likely copied into both attributes.",
 		)
 		return
 	}
 
+	if staticToken == "" && !uuidPattern.MatchString(roleID) && uuidPattern.MatchString(secretID) {
+		resp.Diagnostics.AddWarning(
+			"Role ID and Secret ID May Be Swapped",
+			"'secret_id' looks like a UUID but 'role_id' does not. Role IDs are usually UUIDs, "+
+				"so check that the two values have not been swapped.",
+		)
+	}
+
+	idleConnTimeout := defaultIdleConnTimeout
+	if !config.IdleConnTimeoutSeconds.IsNull() && !config.IdleConnTimeoutSeconds.IsUnknown() {
+		if config.IdleConnTimeoutSeconds.ValueInt64() < 0 {
+			resp.Diagnostics.AddError(
+				"Invalid Idle Connection Timeout",
+				fmt.Sprintf("'idle_conn_timeout_seconds' must not be negative, got %d.", config.IdleConnTimeoutSeconds.ValueInt64()),
+			)
+			return
+		}
+		idleConnTimeout = time.Duration(config.IdleConnTimeoutSeconds.ValueInt64()) * time.Second
+	}
+
+	if !config.CACertFile.IsNull() && !config.CACertPEM.IsNull() {
+		resp.Diagnostics.AddError(
+			"Conflicting CA Certificates",
+			"Set either 'ca_cert_file' or 'ca_cert_pem', not both.",
+		)
+		return
+	}
+	rootCAs, err := loadCACertPool(config.CACertFile.ValueString(), config.CACertPEM.ValueString())
+	if err != nil {
+		resp.Diagnostics.AddError("Invalid CA Certificate", err.Error())
+		return
+	}
+
+	transport := p.transport
+	if transport == nil {
+		transport = newTransport(idleConnTimeout, config.DisableKeepAlives.ValueBool(), rootCAs)
+	}
+
+	httpClient := &http.Client{
+		Transport:     transport,
+		Timeout:       30 * time.Second,
+		CheckRedirect: redirectPolicy(config.AllowSchemeDowngrade.ValueBool()),
+	}
+
+	if wait := config.WaitForUnsealSeconds.ValueInt64(); wait > 0 {
+		if err := waitForUnseal(ctx, httpClient, address, time.Duration(wait)*time.Second); err != nil {
+			resp.Diagnostics.AddError(
+				"Vault Is Sealed",
+				fmt.Sprintf("Vault at %s did not become unsealed within %d seconds: %s", address, wait, err),
+			)
+			return
+		}
+	}
+
+	token := staticToken
+	var tokenExpiry time.Time
+	var login *loginResult
+
+	cacheFile := config.TokenCacheFile.ValueString()
+	if cacheFile != "" && token == "" {
+		cached, err := loadCachedToken(httpClient, cacheFile, address, namespace, namespaceMode, cacheIdentity, tokenHeaderStyle)
+		switch {
+		case err == nil:
+			token = cached.Token
+			tokenExpiry = cached.ExpiresAt
+			tflog.Info(ctx, "Reusing cached Vault token", map[string]interface{}{
+				"token_cache_file": cacheFile,
+			})
+		case errors.Is(err, errInsecureTokenCache):
+			resp.Diagnostics.AddWarning(
+				"Token Cache File Ignored",
+				fmt.Sprintf("%s. Other users may have read the cached token; logging in again and rewriting the "+
+					"file with mode 0600.", err),
+			)
+		case !os.IsNotExist(err):
+			tflog.Debug(ctx, "Cached Vault token not usable, logging in", map[string]interface{}{
+				"reason": err.Error(),
+			})
+		}
+	}
+
+	if token == "" {
+		loginTime := time.Now()
+		var err error
+		login, err = authenticateAppRole(httpClient, dnsRetries, backoff, address, namespace, namespaceMode, roleID, secretID,
+			tokenPath, loginTTL, tokenPolicies)
+		if err != nil {
+			resp.Diagnostics.AddError(
+				"Vault Authentication Failed",
+				fmt.Sprintf("Could not authenticate with Vault at %s: %s", address, err)+loginErrorHint(err),
+			)
+			return
+		}
+		token = login.Token
+		granted := login.Lease
+
+		tflog.Info(ctx, "Authenticated with Vault", map[string]interface{}{
+			"requested_ttl":  loginTTL.String(),
+			"granted_ttl":    granted.String(),
+			"token_accessor": login.Accessor,
+			"token_policies": strings.Join(login.Policies, ","),
+			"renewable":      login.Renewable,
+		})
+		if loginTTL > 0 && granted > 0 && granted < loginTTL {
+			resp.Diagnostics.AddWarning(
+				"Login TTL Shortened by Vault",
+				fmt.Sprintf("'login_ttl' requested %s but Vault granted a token valid for %s, "+
+					"likely because of the role's token_max_ttl. Long applies may outlive the token.", loginTTL, granted),
+			)
+		}
+		if granted > 0 {
+			tokenExpiry = loginTime.Add(granted)
+		}
+		if extra := unrequestedPolicies(tokenPolicies, login.Policies); len(extra) > 0 {
+			resp.Diagnostics.AddWarning(
+				"Token Policies Not Narrowed",
+				fmt.Sprintf("'token_policies' requested %s but the login token also carries %s. The role likely does "+
+					"not allow requesting a subset of its policies at login.",
+					strings.Join(tokenPolicies, ", "), strings.Join(extra, ", ")),
+			)
+		}
+
+		if cacheFile != "" {
+			if err := saveCachedToken(cacheFile, address, cacheIdentity, token, tokenExpiry); err != nil {
+				resp.Diagnostics.AddWarning(
+					"Token Cache Not Written",
+					fmt.Sprintf("Could not write the token to %s: %s. The next run will log in again.", cacheFile, err),
+				)
+			}
+		}
+	}
+
+	if config.TokenInQuery.ValueBool() {
+		resp.Diagnostics.AddWarning(
+			"Token Sent in Query String",
+			"'token_in_query' is enabled: the Vault token is appended to request URLs and may be recorded "+
+				"in proxy or gateway access logs. Only use this when a gateway strips the X-Vault-Token header.",
+		)
+	}
+
 	client := &VaultClient{
-		Address: address,
-		Token:   token,
-		HTTPClient: &http.Client{
-			Timeout: 30 * time.Second,
-		},
+		Address:      address,
+		Token:        token,
+		HTTPClient:   httpClient,
+		ReadCache:    config.ReadCache.ValueBool(),
+		TokenInQuery: config.TokenInQuery.ValueBool(),
+
+		TokenHeaderStyle:  tokenHeaderStyle,
+		OperationLogLevel: operationLogLevel,
+
+		RequestJSONIndent:      requestJSONFormat == jsonFormatIndent,
+		RequestTrailingNewline: config.RequestTrailingNewline.ValueBool(),
+
+		ConditionalReads: config.ConditionalReads.ValueBool(),
+		DryRun:           config.DryRun.ValueBool(),
+		ReadOnly:         config.ReadOnly.ValueBool(),
+		MaskLogValues:    config.MaskLogValues.IsNull() || config.MaskLogValues.ValueBool(),
+		VerifyWrite:      config.VerifyWrite.ValueBool(),
+		MaxRequestBytes:  maxRequestBytes,
+		MaxSecretBytes:   int(config.MaxSecretBytes.ValueInt64()),
+
+		ContentAddressedWrites: config.ContentAddressedWrites.ValueBool(),
+		DefaultCustomMetadata:  defaultCustomMetadata,
+		AllowValueCommands:     config.AllowValueCommands.ValueBool(),
+
+		Headers:       headers,
+		Namespace:     namespace,
+		NamespaceMode: namespaceMode,
+		DNSRetries:    dnsRetries,
+		MaxRetries:    maxRetries,
+		Backoff:       backoff,
+		TokenExpiry:   tokenExpiry,
+
+		ResponseDataPath: responseDataPath,
+		ControlGroupWait: time.Duration(config.ControlGroupWaitSeconds.ValueInt64()) * time.Second,
+
+		cache: &clientCache{},
+	}
+	if login != nil {
+		client.TokenAccessor = login.Accessor
+		client.TokenPolicies = login.Policies
+		client.TokenRenewable = login.Renewable
 	}
 
 	resp.DataSourceData = client
@@ -120,53 +812,328 @@ func (p *VaultPatchProvider) Configure(ctx context.Context, req provider.Configu
 func (p *VaultPatchProvider) Resources(_ context.Context) []func() resource.Resource {
 	return []func() resource.Resource{
 		NewKvKeysResource,
+		NewKvRepairResource,
+		NewKvRotatingKeyResource,
+		NewAppRoleRoleResource,
+		NewWrappedSecretResource,
 	}
 }
 
 func (p *VaultPatchProvider) DataSources(_ context.Context) []func() datasource.DataSource {
-	return nil
+	return []func() datasource.DataSource{
+		NewKvSecretDataSource,
+		NewMountsDataSource,
+		NewReplicationStatusDataSource,
+		NewKvKeyOwnershipDataSource,
+		NewKvSecretAgeDataSource,
+		NewKvAssertDataSource,
+	}
 }
 
-func authenticateAppRole(address, roleID, secretID string) (string, error) {
-	loginURL := fmt.Sprintf("%s/v1/auth/approle/login", address)
+// configuredNamespace returns the provider namespace: the namespace
+// attribute when set, else VAULT_NAMESPACE like the Vault CLI.
+func configuredNamespace(attr types.String) string {
+	namespace := attr.ValueString()
+	if attr.IsNull() {
+		namespace = os.Getenv("VAULT_NAMESPACE")
+	}
+	return strings.Trim(namespace, "/")
+}
 
-	payload := map[string]string{
+// authenticateAppRole logs in to the AppRole mount of namespace (the root
+// namespace when empty) and returns the parsed login response, whose Lease
+// is the TTL Vault granted. A zero ttl leaves the TTL to the role and no
+// policies leave the policies to the role. A host name that fails to
+// resolve is retried dnsRetries times with backoff.
+func authenticateAppRole(httpClient *http.Client, dnsRetries int, backoff retryBackoff,
+	address, namespace, namespaceMode, roleID, secretID, tokenPath string,
+	ttl time.Duration, policies []string) (*loginResult, error) {
+	loginURL := fmt.Sprintf("%s/v1/auth/approle/login", namespaceBase(normalizeAddress(address), namespace, namespaceMode))
+
+	payload := map[string]interface{}{
 		"role_id":   roleID,
 		"secret_id": secretID,
 	}
+	if ttl > 0 {
+		payload["ttl"] = fmt.Sprintf("%ds", int64(ttl.Seconds()))
+	}
+	if len(policies) > 0 {
+		payload["token_policies"] = policies
+	}
 	body, err := json.Marshal(payload)
 	if err != nil {
-		return "", fmt.Errorf("failed to marshal login payload: %w", err)
+		return nil, fmt.Errorf("failed to marshal login payload: %w", err)
+	}
+
+	req, err := http.NewRequest("POST", loginURL, bytes.NewBuffer(body))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create login request: %w", err)
 	}
+	req.Header.Set("Content-Type", "application/json")
+	setNamespaceHeader(req, namespace, namespaceMode)
 
-	resp, err := http.Post(loginURL, "application/json", bytes.NewBuffer(body))
+	resp, err := sendWithDNSRetries(httpClient, req, dnsRetries, backoff)
 	if err != nil {
-		return "", fmt.Errorf("failed to send login request: %w", err)
+		return nil, fmt.Errorf("failed to send login request: %w", err)
 	}
 	defer resp.Body.Close()
 
 	respBody, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return "", fmt.Errorf("failed to read login response: %w", err)
+		return nil, fmt.Errorf("failed to read login response: %w", err)
 	}
 
 	if resp.StatusCode != http.StatusOK {
-		return "", fmt.Errorf("vault returned status %d: %s", resp.StatusCode, string(respBody))
+		return nil, fmt.Errorf("vault returned status %d: %s", resp.StatusCode, string(respBody))
 	}
 
-	var result struct {
+	return parseLoginResponse(respBody, tokenPath)
+}
+
+// loginResult is what the provider keeps from a login response.
+type loginResult struct {
+	Token     string
+	Accessor  string
+	Lease     time.Duration
+	Renewable bool
+	Policies  []string
+	Metadata  map[string]string
+}
+
+// parseLoginResponse decodes the response of any Vault login endpoint. The
+// token is looked up at tokenPath, for backends that nest it differently;
+// the other fields come from the standard "auth" block and are left empty
+// when absent.
+func parseLoginResponse(body []byte, tokenPath string) (*loginResult, error) {
+	var doc interface{}
+	if err := json.Unmarshal(body, &doc); err != nil {
+		return nil, fmt.Errorf("failed to parse login response: %w", err)
+	}
+
+	value, ok := lookupJSONPath(doc, tokenPath)
+	if !ok {
+		return nil, fmt.Errorf("login response has no value at %q", tokenPath)
+	}
+
+	token, ok := value.(string)
+	if !ok {
+		return nil, fmt.Errorf("login response value at %q is not a string", tokenPath)
+	}
+
+	if token == "" {
+		return nil, fmt.Errorf("vault returned empty client token")
+	}
+
+	var parsed struct {
 		Auth struct {
-			ClientToken string `json:"client_token"`
+			Accessor      string            `json:"accessor"`
+			LeaseDuration float64           `json:"lease_duration"`
+			Renewable     bool              `json:"renewable"`
+			TokenPolicies []string          `json:"token_policies"`
+			Policies      []string          `json:"policies"`
+			Metadata      map[string]string `json:"metadata"`
 		} `json:"auth"`
 	}
+	// The auth block is informational; a backend that shapes it differently
+	// still yields a usable token.
+	_ = json.Unmarshal(body, &parsed)
 
-	if err := json.Unmarshal(respBody, &result); err != nil {
-		return "", fmt.Errorf("failed to parse login response: %w", err)
+	result := &loginResult{
+		Token:     token,
+		Accessor:  parsed.Auth.Accessor,
+		Lease:     time.Duration(parsed.Auth.LeaseDuration) * time.Second,
+		Renewable: parsed.Auth.Renewable,
+		Policies:  parsed.Auth.TokenPolicies,
+		Metadata:  parsed.Auth.Metadata,
 	}
+	if len(result.Policies) == 0 {
+		result.Policies = parsed.Auth.Policies
+	}
+	return result, nil
+}
 
-	if result.Auth.ClientToken == "" {
-		return "", fmt.Errorf("vault returned empty client token")
+// unrequestedPolicies returns the policies of granted that were not in
+// requested, ignoring "default", which Vault attaches to most tokens. Nothing
+// is reported when no policies were requested.
+func unrequestedPolicies(requested, granted []string) []string {
+	if len(requested) == 0 {
+		return nil
 	}
+	wanted := make(map[string]bool, len(requested))
+	for _, policy := range requested {
+		wanted[policy] = true
+	}
+	var extra []string
+	for _, policy := range granted {
+		if !wanted[policy] && policy != "default" {
+			extra = append(extra, policy)
+		}
+	}
+	return extra
+}
 
-	return result.Auth.ClientToken, nil
+// unsealPollInterval is how often sys/health is polled while waiting for
+// Vault to be unsealed.
+const unsealPollInterval = 2 * time.Second
+
+// waitForUnseal polls the unauthenticated sys/health endpoint until Vault
+// reports it is initialized and unsealed, or timeout elapses. Connection
+// errors are retried, since a restarting server may not be listening yet.
+func waitForUnseal(ctx context.Context, httpClient *http.Client, address string, timeout time.Duration) error {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	healthURL := fmt.Sprintf("%s/v1/sys/health", address)
+	for {
+		state, err := checkSealStatus(ctx, httpClient, healthURL)
+		if err == nil && state == "" {
+			return nil
+		}
+		if err != nil {
+			state = err.Error()
+		}
+		tflog.Info(ctx, "Waiting for Vault to be unsealed", map[string]interface{}{
+			"status": state,
+		})
+
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("last status: %s", state)
+		case <-time.After(unsealPollInterval):
+		}
+	}
+}
+
+// checkSealStatus returns an empty string when Vault is ready, or a short
+// description of why it is not.
+func checkSealStatus(ctx context.Context, httpClient *http.Client, healthURL string) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", healthURL, nil)
+	if err != nil {
+		return "", err
+	}
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	var health struct {
+		Initialized bool `json:"initialized"`
+		Sealed      bool `json:"sealed"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&health); err != nil {
+		return "", fmt.Errorf("failed to parse sys/health response (status %d): %w", resp.StatusCode, err)
+	}
+
+	switch {
+	case !health.Initialized:
+		return "not initialized", nil
+	case health.Sealed:
+		return "sealed", nil
+	}
+	return "", nil
+}
+
+// normalizeAddress trims trailing slashes so joined URLs never contain "//v1".
+func normalizeAddress(address string) string {
+	return strings.TrimRight(address, "/")
+}
+
+const defaultLoginTokenPath = "auth.client_token"
+
+const (
+	defaultDNSRetries = 3
+	defaultMaxRetries = 3
+
+	defaultIdleConnTimeout = 90 * time.Second
+)
+
+// newTransport returns a copy of http.DefaultTransport with the given idle
+// connection timeout and keep-alive setting. A non-nil rootCAs replaces the
+// system roots for verifying Vault's certificate.
+func newTransport(idleConnTimeout time.Duration, disableKeepAlives bool, rootCAs *x509.CertPool) *http.Transport {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.IdleConnTimeout = idleConnTimeout
+	transport.DisableKeepAlives = disableKeepAlives
+	if rootCAs != nil {
+		transport.TLSClientConfig = &tls.Config{
+			MinVersion: tls.VersionTLS12,
+			RootCAs:    rootCAs,
+		}
+	}
+	return transport
+}
+
+// loadCACertPool returns a pool of the PEM certificates in file or pemData,
+// whichever is set, or nil when neither is.
+func loadCACertPool(file, pemData string) (*x509.CertPool, error) {
+	source := "'ca_cert_pem'"
+	if file != "" {
+		raw, err := os.ReadFile(file)
+		if err != nil {
+			return nil, fmt.Errorf("could not read 'ca_cert_file': %w", err)
+		}
+		pemData = string(raw)
+		source = file
+	}
+	if pemData == "" {
+		return nil, nil
+	}
+
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM([]byte(pemData)) {
+		return nil, fmt.Errorf("%s contains no PEM-encoded certificates", source)
+	}
+	return pool, nil
+}
+
+var uuidPattern = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+
+// loginErrorHint adds guidance for the credential errors AppRole login
+// reports, which do not say which of the two values is wrong.
+func loginErrorHint(err error) string {
+	msg := strings.ToLower(err.Error())
+	switch {
+	case strings.Contains(msg, "invalid role or secret id"):
+		return "\n\nVault rejected the AppRole credentials. Check that 'role_id' and 'secret_id' belong to the same role, " +
+			"have not been swapped, and that the secret ID has not expired or exceeded its use limit. " +
+			"Also make sure 'secret_id' holds the secret ID itself and not its secret_id_accessor: both are UUIDs, " +
+			"but only the secret ID can be used to log in."
+	case strings.Contains(msg, "invalid role id"):
+		return "\n\nVault does not recognize 'role_id'. Check that it has not been swapped with 'secret_id'."
+	case strings.Contains(msg, "invalid secret id"):
+		return "\n\nVault does not recognize 'secret_id'. It may have expired, been used up, been swapped with 'role_id', " +
+			"or be a secret_id_accessor rather than the secret ID itself."
+	}
+	return ""
+}
+
+func validJSONPath(path string) bool {
+	if path == "" {
+		return false
+	}
+	for _, segment := range strings.Split(path, ".") {
+		if segment == "" {
+			return false
+		}
+	}
+	return true
+}
+
+// lookupJSONPath walks a decoded JSON document along a dotted path of object
+// keys, e.g. "auth.client_token".
+func lookupJSONPath(doc interface{}, path string) (interface{}, bool) {
+	current := doc
+	for _, segment := range strings.Split(path, ".") {
+		obj, ok := current.(map[string]interface{})
+		if !ok {
+			return nil, false
+		}
+		current, ok = obj[segment]
+		if !ok {
+			return nil, false
+		}
+	}
+	return current, true
 }
@@ -0,0 +1,209 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework/provider"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+)
+
+// roundTripFunc is an http.RoundTripper answering from a function.
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+// configureProvider configures p with the given attributes, leaving the
+// others null, and returns the response.
+func configureProvider(t *testing.T, p *VaultPatchProvider, attrs map[string]tftypes.Value) *provider.ConfigureResponse {
+	t.Helper()
+	ctx := context.Background()
+
+	var schemaResp provider.SchemaResponse
+	p.Schema(ctx, provider.SchemaRequest{}, &schemaResp)
+	typ := schemaResp.Schema.Type().TerraformType(ctx).(tftypes.Object)
+	values := make(map[string]tftypes.Value, len(typ.AttributeTypes))
+	for name, attrType := range typ.AttributeTypes {
+		values[name] = tftypes.NewValue(attrType, nil)
+	}
+	for name, value := range attrs {
+		values[name] = value
+	}
+
+	resp := &provider.ConfigureResponse{}
+	p.Configure(ctx, provider.ConfigureRequest{
+		Config: tfsdk.Config{Schema: schemaResp.Schema, Raw: tftypes.NewValue(typ, values)},
+	}, resp)
+	return resp
+}
+
+func TestConfigureUsesTransport(t *testing.T) {
+	t.Setenv("VAULT_NAMESPACE", "")
+
+	var requests []string
+	p := &VaultPatchProvider{
+		transport: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+			requests = append(requests, req.Method+" "+req.URL.String())
+			if req.URL.Path != "/v1/auth/approle/login" {
+				return nil, io.ErrUnexpectedEOF
+			}
+			var payload map[string]interface{}
+			if err := json.NewDecoder(req.Body).Decode(&payload); err != nil {
+				t.Errorf("decoding login payload: %s", err)
+			}
+			if payload["role_id"] != "ci" || payload["secret_id"] != "s3cret" {
+				t.Errorf("login payload = %v", payload)
+			}
+
+			rec := httptest.NewRecorder()
+			writeJSON(t, rec, map[string]interface{}{
+				"auth": map[string]interface{}{
+					"client_token":   "s.login-token",
+					"accessor":       "acc",
+					"lease_duration": 3600,
+					"renewable":      true,
+					"policies":       []string{"default", "ci"},
+				},
+			})
+			return rec.Result(), nil
+		}),
+	}
+
+	resp := configureProvider(t, p, map[string]tftypes.Value{
+		"address":   tftypes.NewValue(tftypes.String, "https://vault.invalid:8200/"),
+		"role_id":   tftypes.NewValue(tftypes.String, "ci"),
+		"secret_id": tftypes.NewValue(tftypes.String, "s3cret"),
+	})
+	if resp.Diagnostics.HasError() {
+		t.Fatalf("unexpected errors: %v", resp.Diagnostics)
+	}
+	if want := "POST https://vault.invalid:8200/v1/auth/approle/login"; len(requests) != 1 || requests[0] != want {
+		t.Errorf("requests = %v, want [%s]", requests, want)
+	}
+
+	client, ok := resp.ResourceData.(*VaultClient)
+	if !ok {
+		t.Fatalf("ResourceData = %T, want *VaultClient", resp.ResourceData)
+	}
+	if client.Token != "s.login-token" || client.TokenAccessor != "acc" {
+		t.Errorf("token = %q, accessor = %q", client.Token, client.TokenAccessor)
+	}
+	if client.Address != "https://vault.invalid:8200" {
+		t.Errorf("address = %q", client.Address)
+	}
+}
+
+func TestParseLoginResponse(t *testing.T) {
+	// An AppRole login response as returned by Vault.
+	body := []byte(`{
+		"request_id": "1a2b3c",
+		"lease_id": "",
+		"renewable": false,
+		"lease_duration": 0,
+		"data": null,
+		"auth": {
+			"client_token": "hvs.CAESIJ",
+			"accessor": "hmac-accessor",
+			"policies": ["default", "ci"],
+			"token_policies": ["ci", "default"],
+			"metadata": {"role_name": "ci"},
+			"lease_duration": 1200,
+			"renewable": true,
+			"entity_id": "e1",
+			"token_type": "service",
+			"orphan": true
+		}
+	}`)
+
+	got, err := parseLoginResponse(body, defaultLoginTokenPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := &loginResult{
+		Token:     "hvs.CAESIJ",
+		Accessor:  "hmac-accessor",
+		Lease:     20 * time.Minute,
+		Renewable: true,
+		Policies:  []string{"ci", "default"},
+		Metadata:  map[string]string{"role_name": "ci"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("parseLoginResponse = %+v, want %+v", got, want)
+	}
+}
+
+func TestParseLoginResponseWithoutToken(t *testing.T) {
+	tests := map[string]string{
+		"no auth":         `{"request_id": "1a2b3c", "data": null, "auth": null, "errors": []}`,
+		"no client_token": `{"auth": {"accessor": "hmac-accessor", "lease_duration": 1200}}`,
+		"empty token":     `{"auth": {"client_token": ""}}`,
+	}
+	for name, body := range tests {
+		_, err := parseLoginResponse([]byte(body), defaultLoginTokenPath)
+		if err == nil {
+			t.Errorf("%s: expected an error", name)
+			continue
+		}
+		if name != "empty token" && !strings.Contains(err.Error(), `no value at "auth.client_token"`) {
+			t.Errorf("%s: error = %q, want it to name the token path", name, err)
+		}
+	}
+}
+
+func TestNormalizeAddress(t *testing.T) {
+	tests := []struct {
+		address, want string
+	}{
+		{"https://vault.example.com:8200", "https://vault.example.com:8200"},
+		{"https://vault.example.com:8200/", "https://vault.example.com:8200"},
+		{"https://vault.example.com:8200//", "https://vault.example.com:8200"},
+		{"https://example.com/vault", "https://example.com/vault"},
+		{"https://example.com/vault/", "https://example.com/vault"},
+	}
+	for _, tt := range tests {
+		if got := normalizeAddress(tt.address); got != tt.want {
+			t.Errorf("normalizeAddress(%q) = %q, want %q", tt.address, got, tt.want)
+		}
+	}
+}
+
+func TestAuthenticateAppRoleRetriesDNSErrors(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(t, w, map[string]interface{}{"auth": map[string]interface{}{"client_token": "s.issued"}})
+	}))
+	defer srv.Close()
+	httpClient := srv.Client()
+	transport := &dnsFailingTransport{failures: 2, next: httpClient.Transport}
+	httpClient.Transport = transport
+
+	login, err := authenticateAppRole(httpClient, 2, retryBackoff{Jitter: retryJitterNone}, srv.URL, "", "", "role", "secret",
+		defaultLoginTokenPath, 0, nil)
+	if err != nil {
+		t.Fatalf("expected the login to succeed after retries, got %s", err)
+	}
+	if login.Token != "s.issued" {
+		t.Errorf("token = %q, want %q", login.Token, "s.issued")
+	}
+	if transport.calls != 3 {
+		t.Errorf("expected 3 attempts, got %d", transport.calls)
+	}
+
+	transport.calls, transport.failures = 0, 10
+	if _, err := authenticateAppRole(httpClient, 2, retryBackoff{Jitter: retryJitterNone}, srv.URL, "", "", "role", "secret",
+		defaultLoginTokenPath, 0, nil); err == nil || !isDNSError(err) {
+		t.Fatalf("expected a DNS error, got %v", err)
+	}
+	if transport.calls != 3 {
+		t.Errorf("expected 3 attempts, got %d", transport.calls)
+	}
+}
@@ -0,0 +1,462 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	tfpath "github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringdefault"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+var _ resource.Resource = &AppRoleRoleResource{}
+var _ resource.ResourceWithImportState = &AppRoleRoleResource{}
+var _ resource.ResourceWithValidateConfig = &AppRoleRoleResource{}
+
+type AppRoleRoleResource struct {
+	client *VaultClient
+}
+
+type AppRoleRoleResourceModel struct {
+	ID        types.String `tfsdk:"id"`
+	AuthMount types.String `tfsdk:"auth_mount"`
+	RoleName  types.String `tfsdk:"role_name"`
+
+	TokenPolicies   types.List  `tfsdk:"token_policies"`
+	TokenTTL        types.Int64 `tfsdk:"token_ttl"`
+	TokenMaxTTL     types.Int64 `tfsdk:"token_max_ttl"`
+	TokenBoundCIDRs types.List  `tfsdk:"token_bound_cidrs"`
+
+	BindSecretID       types.Bool  `tfsdk:"bind_secret_id"`
+	SecretIDTTL        types.Int64 `tfsdk:"secret_id_ttl"`
+	SecretIDNumUses    types.Int64 `tfsdk:"secret_id_num_uses"`
+	SecretIDBoundCIDRs types.List  `tfsdk:"secret_id_bound_cidrs"`
+
+	RoleID types.String `tfsdk:"role_id"`
+}
+
+// int64Fields maps the Vault field names of the role's numeric settings to
+// their attributes.
+func (m *AppRoleRoleResourceModel) int64Fields() map[string]*types.Int64 {
+	return map[string]*types.Int64{
+		"token_ttl":          &m.TokenTTL,
+		"token_max_ttl":      &m.TokenMaxTTL,
+		"secret_id_ttl":      &m.SecretIDTTL,
+		"secret_id_num_uses": &m.SecretIDNumUses,
+	}
+}
+
+// listFields maps the Vault field names of the role's list settings to
+// their attributes.
+func (m *AppRoleRoleResourceModel) listFields() map[string]*types.List {
+	return map[string]*types.List{
+		"token_policies":        &m.TokenPolicies,
+		"token_bound_cidrs":     &m.TokenBoundCIDRs,
+		"secret_id_bound_cidrs": &m.SecretIDBoundCIDRs,
+	}
+}
+
+func NewAppRoleRoleResource() resource.Resource {
+	return &AppRoleRoleResource{}
+}
+
+func (r *AppRoleRoleResource) Metadata(_ context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_approle_role"
+}
+
+func (r *AppRoleRoleResource) Schema(_ context.Context, _ resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		Description: "Manages an AppRole role (auth/<auth_mount>/role/<role_name>). Only the settings set here are " +
+			"managed; others keep whatever value Vault has, and removing a setting from the configuration stops " +
+			"managing it without resetting it.",
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				Description: "The unique identifier for this resource (auth_mount/role_name).",
+				Computed:    true,
+			},
+			"auth_mount": schema.StringAttribute{
+				Description: "The path the AppRole auth method is mounted at, without the 'auth/' prefix. Defaults to 'approle'.",
+				Optional:    true,
+				Computed:    true,
+				Default:     stringdefault.StaticString("approle"),
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"role_name": schema.StringAttribute{
+				Description: "The name of the role.",
+				Required:    true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"token_policies": schema.ListAttribute{
+				Description: "Policies attached to tokens issued for this role.",
+				Optional:    true,
+				ElementType: types.StringType,
+			},
+			"token_ttl": schema.Int64Attribute{
+				Description: "Default TTL of issued tokens, in seconds.",
+				Optional:    true,
+			},
+			"token_max_ttl": schema.Int64Attribute{
+				Description: "Maximum TTL of issued tokens, in seconds.",
+				Optional:    true,
+			},
+			"token_bound_cidrs": schema.ListAttribute{
+				Description: "CIDR blocks allowed to use issued tokens.",
+				Optional:    true,
+				ElementType: types.StringType,
+			},
+			"bind_secret_id": schema.BoolAttribute{
+				Description: "Whether a secret ID is required to log in with this role.",
+				Optional:    true,
+			},
+			"secret_id_ttl": schema.Int64Attribute{
+				Description: "TTL of secret IDs generated for this role, in seconds. 0 means no expiry.",
+				Optional:    true,
+			},
+			"secret_id_num_uses": schema.Int64Attribute{
+				Description: "Number of logins a secret ID allows. 0 means unlimited.",
+				Optional:    true,
+			},
+			"secret_id_bound_cidrs": schema.ListAttribute{
+				Description: "CIDR blocks allowed to log in with secret IDs of this role.",
+				Optional:    true,
+				ElementType: types.StringType,
+			},
+			"role_id": schema.StringAttribute{
+				Description: "The role ID, to be used as 'role_id' when logging in.",
+				Computed:    true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+		},
+	}
+}
+
+func (r *AppRoleRoleResource) ValidateConfig(ctx context.Context, req resource.ValidateConfigRequest, resp *resource.ValidateConfigResponse) {
+	var config AppRoleRoleResourceModel
+	resp.Diagnostics.Append(req.Config.Get(ctx, &config)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if !config.AuthMount.IsNull() && !config.AuthMount.IsUnknown() {
+		mount := config.AuthMount.ValueString()
+		if mount == "" || strings.HasPrefix(mount, "/") || strings.HasSuffix(mount, "/") || strings.HasPrefix(mount, "auth/") {
+			resp.Diagnostics.AddAttributeError(
+				tfpath.Root("auth_mount"),
+				"Invalid Auth Mount",
+				fmt.Sprintf("'auth_mount' must be the mount path without the 'auth/' prefix or surrounding slashes "+
+					"(e.g., 'approle'), got %q.", mount),
+			)
+		}
+	}
+
+	if !config.RoleName.IsUnknown() {
+		if name := config.RoleName.ValueString(); name == "" || strings.Contains(name, "/") {
+			resp.Diagnostics.AddAttributeError(
+				tfpath.Root("role_name"),
+				"Invalid Role Name",
+				fmt.Sprintf("'role_name' must be non-empty and may not contain '/', got %q.", name),
+			)
+		}
+	}
+}
+
+func (r *AppRoleRoleResource) Configure(_ context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+
+	client, ok := req.ProviderData.(*VaultClient)
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Resource Configure Type",
+			"Expected *VaultClient, got something else.",
+		)
+		return
+	}
+
+	r.client = client
+}
+
+func (r *AppRoleRoleResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	if r.client.ReadOnly {
+		addReadOnlyError(&resp.Diagnostics)
+		return
+	}
+
+	var plan AppRoleRoleResourceModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	resp.Diagnostics.Append(r.write(ctx, &plan)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &plan)...)
+}
+
+func (r *AppRoleRoleResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	var state AppRoleRoleResourceModel
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	authMount := state.AuthMount.ValueString()
+	name := state.RoleName.ValueString()
+
+	data, err := r.client.readAppRole(ctx, authMount, name)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Failed to Read AppRole Role",
+			fmt.Sprintf("Could not read role %q on auth/%s: %s", name, authMount, err),
+		)
+		return
+	}
+	if data == nil {
+		tflog.Warn(ctx, "AppRole role no longer exists in Vault, removing from state", map[string]interface{}{
+			"auth_mount": authMount,
+			"role_name":  name,
+		})
+		resp.State.RemoveResource(ctx)
+		return
+	}
+
+	resp.Diagnostics.Append(applyAppRoleFields(&state, data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	roleID, err := r.client.readAppRoleID(ctx, authMount, name)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Failed to Read Role ID",
+			fmt.Sprintf("Could not read the role ID of %q on auth/%s: %s", name, authMount, err),
+		)
+		return
+	}
+	state.RoleID = types.StringValue(roleID)
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &state)...)
+}
+
+func (r *AppRoleRoleResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	if r.client.ReadOnly {
+		addReadOnlyError(&resp.Diagnostics)
+		return
+	}
+
+	var plan AppRoleRoleResourceModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	resp.Diagnostics.Append(r.write(ctx, &plan)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &plan)...)
+}
+
+func (r *AppRoleRoleResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	if r.client.ReadOnly {
+		addReadOnlyError(&resp.Diagnostics)
+		return
+	}
+
+	var state AppRoleRoleResourceModel
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	authMount := state.AuthMount.ValueString()
+	name := state.RoleName.ValueString()
+
+	if err := r.client.deleteAppRole(ctx, authMount, name); err != nil {
+		resp.Diagnostics.AddError(
+			"Failed to Delete AppRole Role",
+			fmt.Sprintf("Could not delete role %q on auth/%s: %s", name, authMount, err),
+		)
+	}
+}
+
+func (r *AppRoleRoleResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
+	authMount := "approle"
+	name := req.ID
+	if idx := strings.LastIndex(req.ID, "/"); idx >= 0 {
+		authMount = req.ID[:idx]
+		name = req.ID[idx+1:]
+	}
+
+	if authMount == "" || name == "" {
+		resp.Diagnostics.AddError(
+			"Invalid Import ID",
+			"Import ID must be 'role_name' or 'auth_mount/role_name' (e.g., 'approle/my-service').",
+		)
+		return
+	}
+
+	state := AppRoleRoleResourceModel{
+		ID:                 types.StringValue(fmt.Sprintf("%s/%s", authMount, name)),
+		AuthMount:          types.StringValue(authMount),
+		RoleName:           types.StringValue(name),
+		TokenPolicies:      types.ListNull(types.StringType),
+		TokenTTL:           types.Int64Null(),
+		TokenMaxTTL:        types.Int64Null(),
+		TokenBoundCIDRs:    types.ListNull(types.StringType),
+		BindSecretID:       types.BoolNull(),
+		SecretIDTTL:        types.Int64Null(),
+		SecretIDNumUses:    types.Int64Null(),
+		SecretIDBoundCIDRs: types.ListNull(types.StringType),
+		RoleID:             types.StringUnknown(),
+	}
+
+	// Settings are only tracked once configured; refresh fills in role_id.
+	resp.Diagnostics.Append(resp.State.Set(ctx, &state)...)
+}
+
+// write sends the configured settings of model to Vault and records the
+// resulting role ID.
+func (r *AppRoleRoleResource) write(ctx context.Context, model *AppRoleRoleResourceModel) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	authMount := model.AuthMount.ValueString()
+	name := model.RoleName.ValueString()
+
+	fields, fieldDiags := appRoleFields(ctx, model)
+	diags.Append(fieldDiags...)
+	if diags.HasError() {
+		return diags
+	}
+
+	r.client.logOperation(ctx, "Writing AppRole role", map[string]interface{}{
+		"auth_mount": authMount,
+		"role_name":  name,
+		"fields":     strings.Join(sortedFieldNames(fields), ", "),
+	})
+
+	if err := r.client.writeAppRole(ctx, authMount, name, fields); err != nil {
+		if errors.Is(err, errMountMissing) {
+			diags.AddAttributeError(
+				tfpath.Root("auth_mount"),
+				"Auth Mount Not Found",
+				fmt.Sprintf("No auth method is mounted at auth/%s. Enable AppRole there or fix 'auth_mount'.", authMount),
+			)
+			return diags
+		}
+		diags.AddError(
+			"Failed to Write AppRole Role",
+			fmt.Sprintf("Could not write role %q on auth/%s: %s", name, authMount, err),
+		)
+		return diags
+	}
+
+	model.ID = types.StringValue(fmt.Sprintf("%s/%s", authMount, name))
+	model.RoleID = types.StringNull()
+	if r.client.DryRun {
+		addDryRunWarning(&diags, "auth/"+authMount, "role/"+name)
+		return diags
+	}
+
+	roleID, err := r.client.readAppRoleID(ctx, authMount, name)
+	if err != nil {
+		diags.AddError(
+			"Failed to Read Role ID",
+			fmt.Sprintf("Could not read the role ID of %q on auth/%s: %s", name, authMount, err),
+		)
+		return diags
+	}
+	model.RoleID = types.StringValue(roleID)
+	return diags
+}
+
+// appRoleFields returns the payload for the settings configured in model.
+func appRoleFields(ctx context.Context, model *AppRoleRoleResourceModel) (map[string]interface{}, diag.Diagnostics) {
+	var diags diag.Diagnostics
+	fields := make(map[string]interface{})
+
+	for name, value := range model.int64Fields() {
+		if !value.IsNull() && !value.IsUnknown() {
+			fields[name] = value.ValueInt64()
+		}
+	}
+	for name, value := range model.listFields() {
+		if value.IsNull() || value.IsUnknown() {
+			continue
+		}
+		var items []string
+		diags.Append(value.ElementsAs(ctx, &items, false)...)
+		fields[name] = items
+	}
+	if !model.BindSecretID.IsNull() && !model.BindSecretID.IsUnknown() {
+		fields["bind_secret_id"] = model.BindSecretID.ValueBool()
+	}
+
+	return fields, diags
+}
+
+// applyAppRoleFields copies the settings in data into the attributes of
+// model that are managed (non-null).
+func applyAppRoleFields(model *AppRoleRoleResourceModel, data map[string]interface{}) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	for name, value := range model.int64Fields() {
+		if value.IsNull() {
+			continue
+		}
+		if number, ok := data[name].(float64); ok {
+			*value = types.Int64Value(int64(number))
+		}
+	}
+	for name, value := range model.listFields() {
+		if value.IsNull() {
+			continue
+		}
+		raw, _ := data[name].([]interface{})
+		items := make([]attr.Value, 0, len(raw))
+		for _, item := range raw {
+			items = append(items, types.StringValue(fmt.Sprintf("%v", item)))
+		}
+		list, listDiags := types.ListValue(types.StringType, items)
+		diags.Append(listDiags...)
+		*value = list
+	}
+	if !model.BindSecretID.IsNull() {
+		if bind, ok := data["bind_secret_id"].(bool); ok {
+			model.BindSecretID = types.BoolValue(bind)
+		}
+	}
+
+	return diags
+}
+
+// sortedFieldNames returns the keys of fields in sorted order, for logging.
+func sortedFieldNames(fields map[string]interface{}) []string {
+	names := make([]string, 0, len(fields))
+	for name := range fields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
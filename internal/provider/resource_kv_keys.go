@@ -1,23 +1,34 @@
 package provider
 
 import (
-	"bytes"
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
-	"io"
-	"net/http"
 	"sort"
+	"strconv"
 	"strings"
+	"time"
 
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	tfpath "github.com/hashicorp/terraform-plugin-framework/path"
 	"github.com/hashicorp/terraform-plugin-framework/resource"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/int64default"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/int64planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
 	"github.com/hashicorp/terraform-plugin-framework/types"
 	"github.com/hashicorp/terraform-plugin-log/tflog"
 )
 
 var _ resource.Resource = &KvKeysResource{}
 var _ resource.ResourceWithImportState = &KvKeysResource{}
+var _ resource.ResourceWithValidateConfig = &KvKeysResource{}
+var _ resource.ResourceWithModifyPlan = &KvKeysResource{}
 
 type KvKeysResource struct {
 	client *VaultClient
@@ -28,6 +39,55 @@ type KvKeysResourceModel struct {
 	Mount types.String `tfsdk:"mount"`
 	Path  types.String `tfsdk:"path"`
 	Keys  types.Map    `tfsdk:"keys"`
+
+	Workspace      types.String `tfsdk:"workspace"`
+	WorkspaceKeys  types.Map    `tfsdk:"workspace_keys"`
+	JSONPointers   types.Map    `tfsdk:"json_pointers"`
+	JSONFormat     types.String `tfsdk:"json_format"`
+	JSONEscapeHTML types.Bool   `tfsdk:"json_escape_html"`
+	Transforms     types.Map    `tfsdk:"transforms"`
+
+	ValueTypes       types.Map  `tfsdk:"value_types"`
+	ValueCommand     types.List `tfsdk:"value_command"`
+	ValueReadCommand types.List `tfsdk:"value_read_command"`
+	JSONSchema       types.Map  `tfsdk:"json_schema"`
+
+	NullMeansDelete types.Bool  `tfsdk:"null_means_delete"`
+	PinnedVersion   types.Int64 `tfsdk:"pinned_version"`
+	DetectOnly      types.Bool  `tfsdk:"detect_only"`
+	AbsentKeysNull  types.Bool  `tfsdk:"absent_keys_as_null"`
+	AllowEmpty      types.Bool  `tfsdk:"allow_empty_secret"`
+	UndeleteFirst   types.Bool  `tfsdk:"undelete_before_write"`
+	VerifyKeyCount  types.Bool  `tfsdk:"verify_key_count"`
+
+	WarnOnVersionPressure types.Bool  `tfsdk:"warn_on_version_pressure"`
+	VersionPressureMargin types.Int64 `tfsdk:"version_pressure_margin"`
+
+	BackupFile           types.String `tfsdk:"backup_file"`
+	AllowPlaintextBackup types.Bool   `tfsdk:"allow_plaintext_backup"`
+
+	OnConcurrentChange types.String `tfsdk:"on_concurrent_change"`
+	CASMaxRetries      types.Int64  `tfsdk:"cas_max_retries"`
+	CASBackoffMs       types.Int64  `tfsdk:"cas_backoff_ms"`
+	ReservedKeyNames   types.String `tfsdk:"reserved_key_names"`
+	KVVersion          types.Int64  `tfsdk:"kv_version"`
+	FlattenUnder       types.String `tfsdk:"flatten_under"`
+	Headers            types.Map    `tfsdk:"headers"`
+	CustomMetadata     types.Map    `tfsdk:"custom_metadata"`
+	Namespace          types.String `tfsdk:"namespace"`
+	IDFormat           types.String `tfsdk:"id_format"`
+	TemplateMarkers    types.String `tfsdk:"template_markers"`
+	TrailingSlash      types.String `tfsdk:"trailing_slash"`
+	PathNormalization  types.String `tfsdk:"path_normalization"`
+
+	SummarizeLargeValues types.Bool  `tfsdk:"summarize_large_values"`
+	LargeValueBytes      types.Int64 `tfsdk:"large_value_bytes"`
+
+	MountAccessor  types.String `tfsdk:"mount_accessor"`
+	CurrentVersion types.Int64  `tfsdk:"current_version"`
+	LastWritten    types.String `tfsdk:"last_written"`
+	KeysChecksum   types.String `tfsdk:"keys_checksum"`
+	ValueSummaries types.Map    `tfsdk:"large_value_summaries"`
 }
 
 func NewKvKeysResource() resource.Resource {
@@ -40,7 +100,7 @@ func (r *KvKeysResource) Metadata(_ context.Context, req resource.MetadataReques
 
 func (r *KvKeysResource) Schema(_ context.Context, _ resource.SchemaRequest, resp *resource.SchemaResponse) {
 	resp.Schema = schema.Schema{
-		Description: "Manages individual keys within a Vault KV v2 secret path. " +
+		Description: "Manages individual keys within a Vault KV secret path (KV v2 by default, KV v1 via 'kv_version'). " +
 			"Only the specified keys are created, updated, or deleted — other keys in the same path are never touched.",
 		Attributes: map[string]schema.Attribute{
 			"id": schema.StringAttribute{
@@ -48,7 +108,7 @@ func (r *KvKeysResource) Schema(_ context.Context, _ resource.SchemaRequest, res
 				Computed:    true,
 			},
 			"mount": schema.StringAttribute{
-				Description: "The mount path of the KV v2 secrets engine (e.g., 'app_demo').",
+				Description: "The mount path of the KV secrets engine (e.g., 'app_demo').",
 				Required:    true,
 			},
 			"path": schema.StringAttribute{
@@ -57,15 +117,715 @@ func (r *KvKeysResource) Schema(_ context.Context, _ resource.SchemaRequest, res
 			},
 			"keys": schema.MapAttribute{
 				Description: "A map of key-value pairs to manage within the secret. " +
-					"Only these keys will be affected; existing keys not listed here are preserved.",
-				Required:    true,
+					"Only these keys will be affected; existing keys not listed here are preserved. " +
+					"Exactly one of 'keys' or 'workspace_keys' must be set; with 'workspace_keys' this holds the selected entry.",
+				Optional:    true,
+				Computed:    true,
+				Sensitive:   true,
+				ElementType: types.StringType,
+			},
+			"workspace": schema.StringAttribute{
+				Description: "The workspace used to select an entry of 'workspace_keys', normally 'terraform.workspace'.",
+				Optional:    true,
+			},
+			"workspace_keys": schema.MapAttribute{
+				Description: "Key maps per workspace name. The entry matching 'workspace' is managed, falling back to a " +
+					"'default' entry; planning fails if neither exists.",
+				Optional:    true,
+				Sensitive:   true,
+				ElementType: types.MapType{ElemType: types.StringType},
+			},
+			"json_pointers": schema.MapAttribute{
+				Description: "Fields to manage inside keys that hold JSON objects, as a map of key name to a map of " +
+					"JSON Pointer (RFC 6901, e.g. '/db/password') to string value. The rest of each JSON value is preserved. " +
+					"A key may not appear both here and in 'keys'.",
+				Optional:    true,
 				Sensitive:   true,
+				ElementType: types.MapType{ElemType: types.StringType},
+			},
+			"json_format": schema.StringAttribute{
+				Description: "How keys updated through 'json_pointers' are re-encoded: 'compact' or 'indent' " +
+					"(two spaces per level). Defaults to 'compact'.",
+				Optional: true,
+			},
+			"json_escape_html": schema.BoolAttribute{
+				Description: "Whether '<', '>' and '&' are escaped as \\u003c, \\u003e and \\u0026 when keys updated through " +
+					"'json_pointers' are re-encoded. Defaults to true.",
+				Optional: true,
+			},
+			"transforms": schema.MapAttribute{
+				Description: "Normalizations applied to values at write time, as a map of key name to a transform or a " +
+					"'|'-separated pipeline applied left to right (e.g., 'trim|base64encode'): 'trim', 'uppercase' " +
+					"('upper'), 'lowercase' ('lower') or 'base64encode'. State keeps the configured value; on refresh a " +
+					"stored value that still matches the transformed configuration is not reported as drift, and a " +
+					"changed value is decoded when every transform in the pipeline is reversible.",
+				Optional:    true,
+				ElementType: types.StringType,
+			},
+			"value_types": schema.MapAttribute{
+				Description: "JSON types to write keys as, as a map of key name to 'number', 'boolean' or 'string', " +
+					"for consumers that expect real JSON numbers and booleans. Values must be exact JSON literals " +
+					"('8080', '1.5', 'true'); anything else fails. Keys not listed are written as strings, so values " +
+					"such as zip codes are never converted by accident.",
+				Optional:    true,
+				ElementType: types.StringType,
+			},
+			"value_command": schema.ListAttribute{
+				Description: "Program and arguments every value of 'keys' is piped through before it is written, after " +
+					"'transforms', e.g. a corporate encryption tool: the value goes to its stdin and its stdout is stored. " +
+					"Requires 'value_read_command' and the provider's 'allow_value_commands'. The program runs with the " +
+					"provider's environment and sees every managed value in plaintext.",
+				Optional:    true,
+				ElementType: types.StringType,
+			},
+			"value_read_command": schema.ListAttribute{
+				Description: "Program and arguments that invert 'value_command': each stored managed value is piped " +
+					"through it on read before it is compared with the configuration.",
+				Optional:    true,
+				ElementType: types.StringType,
+			},
+			"json_schema": schema.MapAttribute{
+				Description: "JSON Schema documents keyed by key name. Before each write the key's value is parsed as " +
+					"JSON and checked against its schema, and the write fails with the path of every violation. " +
+					"Supports type, enum, const, properties, required, additionalProperties, items, minItems, maxItems, " +
+					"minLength, maxLength, pattern, minimum and maximum; other keywords are rejected, except annotations " +
+					"such as title and description.",
+				Optional:    true,
+				ElementType: types.StringType,
+			},
+			"null_means_delete": schema.BoolAttribute{
+				Description: "When true, a null value in 'keys' deletes that key from the secret instead of being rejected. Defaults to false.",
+				Optional:    true,
+			},
+			"pinned_version": schema.Int64Attribute{
+				Description: "The KV version this resource expects to be current. When configured, plans warn while " +
+					"the live version is newer. When not configured, it follows the version created by this resource's " +
+					"own writes (or the version seen at import), and refresh only warns when a newer version changed " +
+					"the managed keys.",
+				Optional: true,
+				Computed: true,
+			},
+			"detect_only": schema.BoolAttribute{
+				Description: "When true, refresh fails with an error if the managed keys in Vault no longer match the " +
+					"last applied state, instead of reconciling state. Changes made in configuration are unaffected. " +
+					"Defaults to false.",
+				Optional: true,
+			},
+			"absent_keys_as_null": schema.BoolAttribute{
+				Description: "When true, a managed key that no longer exists in Vault is kept in state as null on " +
+					"refresh instead of being dropped, and the resource is kept even when every managed key is gone. " +
+					"The plan then shows the key being set again. Since 'keys' is sensitive, Terraform displays " +
+					"the change as '(sensitive value)'; 'terraform show -json' reveals the null entries. Defaults to false.",
+				Optional: true,
+			},
+			"allow_empty_secret": schema.BoolAttribute{
+				Description: "Whether a write may leave the secret with no keys, e.g. when the last key is removed. " +
+					"When false, the latest version is deleted instead (a soft delete on KV v2), and the apply fails " +
+					"if the token may not delete it. Defaults to true.",
+				Optional: true,
+			},
+			"flatten_under": schema.StringAttribute{
+				Description: "Top-level key of the secret holding a JSON object whose fields are managed instead of the " +
+					"secret's own keys, for layouts like data.config.*. Keys are read from and written back into that " +
+					"object; the secret's other top-level keys are preserved. With it set, an empty object is written " +
+					"rather than the secret being deleted. Changing it does not move keys already written.",
+				Optional: true,
+			},
+			"kv_version": schema.Int64Attribute{
+				Description: "The version of the KV secrets engine at 'mount': 1 or 2. Defaults to 2. " +
+					"Version tracking and check-and-set features require KV v2.",
+				Optional: true,
+				Computed: true,
+				Default:  int64default.StaticInt64(2),
+				PlanModifiers: []planmodifier.Int64{
+					int64planmodifier.RequiresReplaceIf(
+						func(_ context.Context, req planmodifier.Int64Request, resp *int64planmodifier.RequiresReplaceIfFuncResponse) {
+							// State written before kv_version existed holds null; that is KV v2, not a change.
+							resp.RequiresReplace = !req.StateValue.IsNull()
+						},
+						"Moving keys to a different KV engine version requires replacing the resource.",
+						"Moving keys to a different KV engine version requires replacing the resource.",
+					),
+				},
+			},
+			"headers": schema.MapAttribute{
+				Description: "HTTP headers added to this resource's requests, on top of (and overriding) the " +
+					"provider 'headers'. X-Vault-Token, X-Vault-Namespace, Authorization and Content-Type cannot be set.",
+				Optional:    true,
+				ElementType: types.StringType,
+			},
+			"custom_metadata": schema.MapAttribute{
+				Description: "KV v2 custom_metadata entries set on the secret after every write, on top of the provider " +
+					"'default_custom_metadata'; entries here win over defaults and over values already on the secret. " +
+					"Removing an entry stops managing it without deleting it.",
+				Optional:    true,
+				ElementType: types.StringType,
+			},
+			"namespace": schema.StringAttribute{
+				Description: "The Vault Enterprise namespace of the secret, overriding the provider 'namespace' for this " +
+					"resource's requests; the ID is then prefixed with 'ns:<namespace>:'. Changing it requires replacing the resource.",
+				Optional: true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"on_concurrent_change": schema.StringAttribute{
+				Description: "What to do when the secret's version moved past the one recorded in state before an update " +
+					"is written (e.g., another run wrote between plan and apply): 'overwrite' merges onto the latest data " +
+					"and writes, 'error' fails the apply, 'merge-retry' merges and writes with check-and-set, re-merging " +
+					"if another writer races it. Defaults to 'overwrite'.",
+				Optional: true,
+			},
+			"cas_max_retries": schema.Int64Attribute{
+				Description: "How often 'merge-retry' re-reads and re-merges after losing a check-and-set race before " +
+					"failing. Defaults to 3.",
+				Optional: true,
+			},
+			"cas_backoff_ms": schema.Int64Attribute{
+				Description: "Milliseconds 'merge-retry' waits before its first retry; the wait doubles with every " +
+					"further retry up to the provider's 'retry_max_delay_ms', with its 'retry_jitter'. Defaults to the " +
+					"provider's 'retry_base_delay_ms'.",
+				Optional: true,
+			},
+			"undelete_before_write": schema.BoolAttribute{
+				Description: "When the secret's latest version is soft-deleted, undelete it before reading and merging, " +
+					"so the write builds on its keys instead of starting from an empty secret. Requires 'update' on " +
+					"<mount>/undelete/<path> and read on the metadata endpoint. A latest version soft-deleted outside " +
+					"Terraform then shows as missing keys to restore on refresh; without it, refresh fails with an error " +
+					"instead of dropping the resource. KV v2 only. Defaults to false.",
+				Optional: true,
+			},
+			"verify_key_count": schema.BoolAttribute{
+				Description: "After each write, read the written version back and fail if it does not hold exactly the " +
+					"preserved and managed keys, naming any that were lost. A guard for shared paths; costs one extra " +
+					"read per write. Defaults to false.",
+				Optional: true,
+			},
+			"warn_on_version_pressure": schema.BoolAttribute{
+				Description: "After each write, read the path metadata and warn when the number of kept versions is " +
+					"within 'version_pressure_margin' of max_versions (the path's, else the mount's), after which every " +
+					"write silently drops the oldest version. Requires read on the metadata and mount config " +
+					"endpoints. KV v2 only. Defaults to false.",
+				Optional: true,
+			},
+			"version_pressure_margin": schema.Int64Attribute{
+				Description: "How close to max_versions the version count may get before 'warn_on_version_pressure' " +
+					"warns. Defaults to 2.",
+				Optional: true,
+			},
+			"backup_file": schema.StringAttribute{
+				Description: "Local file that receives the configured values of the managed keys in PLAIN TEXT (JSON, mode 0600) after every " +
+					"successful write, for break-glass recovery when Vault is unavailable. Anyone who can read the file, " +
+					"its backups or the machine's disk can read the secrets. Requires allow_plaintext_backup = true.",
+				Optional: true,
+			},
+			"allow_plaintext_backup": schema.BoolAttribute{
+				Description: "Acknowledges that 'backup_file' stores secret values unencrypted. Defaults to false.",
+				Optional:    true,
+			},
+			"id_format": schema.StringAttribute{
+				Description: "Template of the resource ID: '{mount}/{path}', '{address}/{mount}/{path}' (prefixes the " +
+					"provider address, for IDs that are unique across Vault clusters) or '{namespace}/{mount}/{path}' " +
+					"(prefixes 'ns:<namespace>:' with the resource or provider namespace, for IDs that are unique " +
+					"across namespaces). Import accepts every form. Defaults to '{mount}/{path}'.",
+				Optional: true,
+			},
+			"template_markers": schema.StringAttribute{
+				Description: "How to treat 'mount' or 'path' values containing '${', '%{', '{{' or '}}', which usually " +
+					"means a template was not rendered: 'error' or 'warn' (for paths that really contain them). " +
+					"Defaults to 'error'.",
+				Optional: true,
+			},
+			"trailing_slash": schema.StringAttribute{
+				Description: "How to treat a 'path' ending in '/', which in KV addresses a directory (a LIST path) rather " +
+					"than a secret: 'error' or 'strip' to drop the trailing slashes. Defaults to 'error'.",
+				Optional: true,
+			},
+			"path_normalization": schema.StringAttribute{
+				Description: "How to treat 'mount' and 'path' values with duplicate slashes or '.' and '..' segments, " +
+					"which often slip in through templating: 'normalize' collapses and resolves them, 'error' rejects " +
+					"them. A '..' that climbs above the value itself is always rejected. Defaults to 'normalize'.",
+				Optional: true,
+			},
+			"reserved_key_names": schema.StringAttribute{
+				Description: "How to treat managed keys named 'data', 'metadata' or 'options', which are valid but easily " +
+					"confused with the KV v2 envelope: 'warn', 'error' or 'allow'. Defaults to 'warn'.",
+				Optional: true,
+			},
+			"summarize_large_values": schema.BoolAttribute{
+				Description: "Report a short hash and length of every managed value of at least 'large_value_bytes' bytes " +
+					"in 'large_value_summaries', so plans show which large values (certificates, bundles) change " +
+					"without the full sensitive blob. Defaults to false.",
+				Optional: true,
+			},
+			"large_value_bytes": schema.Int64Attribute{
+				Description: "Size in bytes from which 'summarize_large_values' summarizes a value. Defaults to 1024.",
+				Optional:    true,
+			},
+			"mount_accessor": schema.StringAttribute{
+				Description: "The accessor of the KV mount, useful for cross-referencing Vault audit logs. " +
+					"Null when the token is not allowed to read sys/mounts.",
+				Computed: true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"current_version": schema.Int64Attribute{
+				Description: "The live KV version of the secret as of the last read or write.",
+				Computed:    true,
+			},
+			"last_written": schema.StringAttribute{
+				Description: "RFC 3339 timestamp of the last write this resource made to the secret. " +
+					"Null when the keys already matched at creation or the resource was imported.",
+				Computed: true,
+			},
+			"keys_checksum": schema.StringAttribute{
+				Description: "Hex SHA-256 over the sorted key/value pairs of 'keys', for triggering other resources on " +
+					"any managed value change without exposing the values.",
+				Computed: true,
+			},
+			"large_value_summaries": schema.MapAttribute{
+				Description: "Map of key name to 'sha256:<first 12 hex digits>, <n> bytes' for managed values of at " +
+					"least 'large_value_bytes' bytes. Null unless 'summarize_large_values' is set.",
+				Computed:    true,
 				ElementType: types.StringType,
 			},
 		},
 	}
 }
 
+func (r *KvKeysResource) ValidateConfig(ctx context.Context, req resource.ValidateConfigRequest, resp *resource.ValidateConfigResponse) {
+	var config KvKeysResourceModel
+	resp.Diagnostics.Append(req.Config.Get(ctx, &config)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if !config.Mount.IsUnknown() && !config.Path.IsUnknown() && looksSwapped(config.Mount.ValueString(), config.Path.ValueString()) {
+		resp.Diagnostics.AddAttributeWarning(
+			tfpath.Root("mount"),
+			"Mount and Path May Be Swapped",
+			fmt.Sprintf("'mount' is %q and 'path' is %q. 'mount' should be only the secrets engine mount "+
+				"(e.g., 'app') and 'path' the secret's location within it (e.g., 'my-service/secrets'). "+
+				"Ignore this warning if the mount really is nested that deeply.",
+				config.Mount.ValueString(), config.Path.ValueString()),
+		)
+	}
+
+	switch mode := config.TrailingSlash.ValueString(); mode {
+	case "", trailingSlashError:
+		if !config.Path.IsUnknown() {
+			addTrailingSlashError(&resp.Diagnostics, config.Path.ValueString(), "Set trailing_slash = \"strip\" to drop it.")
+		}
+	case trailingSlashStrip:
+		if !config.Path.IsUnknown() && strings.Trim(config.Path.ValueString(), "/") == "" {
+			resp.Diagnostics.AddAttributeError(
+				tfpath.Root("path"),
+				"Empty Path",
+				"'path' has nothing left once its slashes are stripped.",
+			)
+		}
+	default:
+		resp.Diagnostics.AddAttributeError(
+			tfpath.Root("trailing_slash"),
+			"Invalid Trailing Slash Mode",
+			fmt.Sprintf("Must be %q or %q, got %q.", trailingSlashError, trailingSlashStrip, mode),
+		)
+	}
+
+	if mode := config.PathNormalization.ValueString(); validPathNormalization(mode, &resp.Diagnostics) {
+		if !config.Mount.IsUnknown() {
+			cleanSecretPathAttr("mount", "Mount", config.Mount.ValueString(), mode, &resp.Diagnostics)
+		}
+		if !config.Path.IsUnknown() {
+			path := config.Path.ValueString()
+			if config.TrailingSlash.ValueString() == trailingSlashStrip {
+				path = strings.TrimRight(path, "/")
+			}
+			cleanSecretPathAttr("path", "Path", path, mode, &resp.Diagnostics)
+		}
+	}
+
+	switch mode := config.TemplateMarkers.ValueString(); mode {
+	case "", templateMarkersError, templateMarkersWarn:
+		for _, field := range []struct {
+			attr, label string
+			value       types.String
+		}{{"mount", "Mount", config.Mount}, {"path", "Path", config.Path}} {
+			attr, value := field.attr, field.value
+			if value.IsUnknown() {
+				continue
+			}
+			marker, found := templateMarker(value.ValueString())
+			if !found {
+				continue
+			}
+			summary := "Unrendered Template in " + field.label
+			detail := fmt.Sprintf("'%s' is %q, which contains %q. This usually means a template was not rendered "+
+				"(e.g., a literal string passed to templatefile, or a heredoc with escaped interpolation), and the "+
+				"secret would be written to that literal path. Set template_markers = %q if the path is intended.",
+				attr, value.ValueString(), marker, templateMarkersWarn)
+			if mode == templateMarkersWarn {
+				resp.Diagnostics.AddAttributeWarning(tfpath.Root(attr), summary, detail)
+			} else {
+				resp.Diagnostics.AddAttributeError(tfpath.Root(attr), summary, detail)
+			}
+		}
+	default:
+		if !config.TemplateMarkers.IsUnknown() {
+			resp.Diagnostics.AddAttributeError(
+				tfpath.Root("template_markers"),
+				"Invalid Template Markers",
+				fmt.Sprintf("Must be %q or %q, got %q.", templateMarkersError, templateMarkersWarn, mode),
+			)
+		}
+	}
+
+	switch {
+	case config.Keys.IsNull() && config.WorkspaceKeys.IsNull():
+		resp.Diagnostics.AddError(
+			"Missing Keys",
+			"One of 'keys' or 'workspace_keys' must be set.",
+		)
+	case !config.Keys.IsNull() && !config.WorkspaceKeys.IsNull():
+		resp.Diagnostics.AddAttributeError(
+			tfpath.Root("workspace_keys"),
+			"Conflicting Keys",
+			"Only one of 'keys' or 'workspace_keys' may be set.",
+		)
+	case !config.WorkspaceKeys.IsNull() && config.Workspace.IsNull():
+		resp.Diagnostics.AddAttributeError(
+			tfpath.Root("workspace"),
+			"Missing Workspace",
+			"'workspace' must be set when 'workspace_keys' is used (e.g., workspace = terraform.workspace).",
+		)
+	}
+
+	if !config.Keys.IsUnknown() && !config.NullMeansDelete.IsUnknown() {
+		_, nullKeys := splitKeys(config.Keys)
+		if len(nullKeys) > 0 && !config.NullMeansDelete.ValueBool() {
+			resp.Diagnostics.AddError("Null Value in Keys", nullKeysDetail(nullKeys))
+		}
+	}
+
+	if !config.JSONPointers.IsNull() && !config.JSONPointers.IsUnknown() {
+		for key, elem := range config.JSONPointers.Elements() {
+			if _, dup := config.Keys.Elements()[key]; dup {
+				resp.Diagnostics.AddAttributeError(
+					tfpath.Root("json_pointers").AtMapKey(key),
+					"Key Managed Twice",
+					fmt.Sprintf("Key %q is set in both 'keys' and 'json_pointers'; manage it in one place only.", key),
+				)
+			}
+			pointers, ok := elem.(types.Map)
+			if !ok || pointers.IsUnknown() {
+				continue
+			}
+			for pointer := range pointers.Elements() {
+				if _, err := parseJSONPointer(pointer); err != nil {
+					resp.Diagnostics.AddAttributeError(
+						tfpath.Root("json_pointers").AtMapKey(key),
+						"Invalid JSON Pointer",
+						err.Error(),
+					)
+				}
+			}
+		}
+	}
+
+	if !config.ValueTypes.IsNull() && !config.ValueTypes.IsUnknown() {
+		for key, kind := range knownStrings(config.ValueTypes) {
+			switch kind {
+			case valueTypeString, valueTypeNumber, valueTypeBoolean:
+			default:
+				resp.Diagnostics.AddAttributeError(
+					tfpath.Root("value_types").AtMapKey(key),
+					"Invalid Value Type",
+					fmt.Sprintf("Must be %q, %q or %q, got %q.", valueTypeString, valueTypeNumber, valueTypeBoolean, kind),
+				)
+				continue
+			}
+			value, ok := config.Keys.Elements()[key].(types.String)
+			if !ok || value.IsNull() || value.IsUnknown() {
+				continue
+			}
+			if _, err := coerceValue(kind, value.ValueString()); err != nil {
+				resp.Diagnostics.AddAttributeError(
+					tfpath.Root("keys").AtMapKey(key),
+					"Value Does Not Match Type",
+					fmt.Sprintf("'value_types' declares %q a %s, but %s.", key, kind, err),
+				)
+			}
+		}
+	}
+
+	if config.ValueCommand.IsNull() != config.ValueReadCommand.IsNull() {
+		resp.Diagnostics.AddAttributeError(
+			tfpath.Root("value_command"),
+			"Incomplete Value Commands",
+			"'value_command' and 'value_read_command' must be set together, so stored values can be compared with "+
+				"the configuration.",
+		)
+	}
+	for attr, command := range map[string]types.List{"value_command": config.ValueCommand, "value_read_command": config.ValueReadCommand} {
+		if !command.IsNull() && !command.IsUnknown() && len(command.Elements()) == 0 {
+			resp.Diagnostics.AddAttributeError(
+				tfpath.Root(attr),
+				"Empty Command",
+				"The command needs at least the program to run.",
+			)
+		}
+	}
+
+	if !config.Transforms.IsNull() && !config.Transforms.IsUnknown() {
+		for key, elem := range config.Transforms.Elements() {
+			spec, ok := elem.(types.String)
+			if !ok || spec.IsUnknown() {
+				continue
+			}
+			if _, err := parseTransformPipeline(spec.ValueString()); err != nil {
+				resp.Diagnostics.AddAttributeError(
+					tfpath.Root("transforms").AtMapKey(key),
+					"Unknown Transform",
+					err.Error(),
+				)
+			}
+		}
+	}
+
+	if !config.JSONSchema.IsNull() && !config.JSONSchema.IsUnknown() {
+		for key, elem := range config.JSONSchema.Elements() {
+			doc, ok := elem.(types.String)
+			if !ok || doc.IsUnknown() {
+				continue
+			}
+			if _, err := parseJSONSchema(doc.ValueString()); err != nil {
+				resp.Diagnostics.AddAttributeError(
+					tfpath.Root("json_schema").AtMapKey(key),
+					"Invalid JSON Schema",
+					err.Error(),
+				)
+			}
+		}
+	}
+
+	if !config.Headers.IsUnknown() {
+		for name := range config.Headers.Elements() {
+			if isReservedHeader(name) {
+				resp.Diagnostics.AddAttributeError(
+					tfpath.Root("headers").AtMapKey(name),
+					"Reserved Header",
+					fmt.Sprintf("%q is managed by the provider and cannot be set.", name),
+				)
+			}
+		}
+	}
+
+	if !config.BackupFile.IsNull() && !config.AllowPlaintextBackup.IsUnknown() && !config.AllowPlaintextBackup.ValueBool() {
+		resp.Diagnostics.AddAttributeError(
+			tfpath.Root("backup_file"),
+			"Plaintext Backup Not Allowed",
+			"'backup_file' writes secret values to local disk unencrypted. Set allow_plaintext_backup = true to "+
+				"accept that risk.",
+		)
+	}
+
+	if !config.IDFormat.IsNull() && !config.IDFormat.IsUnknown() {
+		switch config.IDFormat.ValueString() {
+		case idFormatMountPath, idFormatAddressMountPath, idFormatNamespaceMountPath:
+		default:
+			resp.Diagnostics.AddAttributeError(
+				tfpath.Root("id_format"),
+				"Invalid ID Format",
+				fmt.Sprintf("Must be %q, %q or %q, got %q.", idFormatMountPath, idFormatAddressMountPath,
+					idFormatNamespaceMountPath, config.IDFormat.ValueString()),
+			)
+		}
+	}
+
+	if !config.JSONFormat.IsNull() && !config.JSONFormat.IsUnknown() {
+		switch config.JSONFormat.ValueString() {
+		case jsonFormatCompact, jsonFormatIndent:
+		default:
+			resp.Diagnostics.AddAttributeError(
+				tfpath.Root("json_format"),
+				"Invalid JSON Format",
+				fmt.Sprintf("Must be %q or %q, got %q.", jsonFormatCompact, jsonFormatIndent, config.JSONFormat.ValueString()),
+			)
+		}
+	}
+
+	if !config.KVVersion.IsNull() && !config.KVVersion.IsUnknown() {
+		switch config.KVVersion.ValueInt64() {
+		case 1:
+			if config.UndeleteFirst.ValueBool() {
+				resp.Diagnostics.AddAttributeError(
+					tfpath.Root("undelete_before_write"),
+					"Undelete Not Supported",
+					"KV v1 has no soft delete; 'undelete_before_write' cannot be used with kv_version = 1.",
+				)
+			}
+			if config.OnConcurrentChange.ValueString() == concurrentChangeMergeRetry {
+				resp.Diagnostics.AddAttributeError(
+					tfpath.Root("on_concurrent_change"),
+					"Check-and-Set Not Supported",
+					"'merge-retry' relies on KV v2 check-and-set writes and cannot be used with kv_version = 1.",
+				)
+			}
+			if len(config.CustomMetadata.Elements()) > 0 {
+				resp.Diagnostics.AddAttributeError(
+					tfpath.Root("custom_metadata"),
+					"Custom Metadata Not Supported",
+					"KV v1 has no metadata; 'custom_metadata' cannot be used with kv_version = 1.",
+				)
+			}
+		case 2:
+		default:
+			resp.Diagnostics.AddAttributeError(
+				tfpath.Root("kv_version"),
+				"Invalid KV Version",
+				fmt.Sprintf("Must be 1 or 2, got %d.", config.KVVersion.ValueInt64()),
+			)
+		}
+	}
+
+	if !config.ReservedKeyNames.IsUnknown() {
+		switch mode := config.ReservedKeyNames.ValueString(); mode {
+		case "", reservedKeyNamesWarn, reservedKeyNamesError, reservedKeyNamesAllow:
+			if mode == reservedKeyNamesAllow {
+				break
+			}
+			for _, key := range reservedKeyNamesIn(config) {
+				summary := "Reserved-Looking Key Name"
+				detail := fmt.Sprintf("Key %q matches a field of the KV v2 request and response envelope. It is stored "+
+					"as a regular key, but readers and tooling may confuse it with the envelope. Set "+
+					"reserved_key_names = %q to silence this.", key, reservedKeyNamesAllow)
+				if mode == reservedKeyNamesError {
+					resp.Diagnostics.AddAttributeError(tfpath.Root("keys").AtMapKey(key), summary, detail)
+				} else {
+					resp.Diagnostics.AddAttributeWarning(tfpath.Root("keys").AtMapKey(key), summary, detail)
+				}
+			}
+		default:
+			resp.Diagnostics.AddAttributeError(
+				tfpath.Root("reserved_key_names"),
+				"Invalid Reserved Key Names",
+				fmt.Sprintf("Must be one of %q, %q or %q, got %q.",
+					reservedKeyNamesWarn, reservedKeyNamesError, reservedKeyNamesAllow, mode),
+			)
+		}
+	}
+
+	for attr, value := range map[string]types.Int64{
+		"cas_max_retries": config.CASMaxRetries,
+		"cas_backoff_ms":  config.CASBackoffMs,
+	} {
+		if value.IsNull() || value.IsUnknown() {
+			continue
+		}
+		if value.ValueInt64() < 0 {
+			resp.Diagnostics.AddAttributeError(
+				tfpath.Root(attr),
+				"Invalid CAS Retry Setting",
+				fmt.Sprintf("'%s' must not be negative, got %d.", attr, value.ValueInt64()),
+			)
+		} else if !config.OnConcurrentChange.IsUnknown() && config.OnConcurrentChange.ValueString() != concurrentChangeMergeRetry {
+			resp.Diagnostics.AddAttributeWarning(
+				tfpath.Root(attr),
+				"CAS Retry Setting Unused",
+				fmt.Sprintf("'%s' only applies when on_concurrent_change is %q.", attr, concurrentChangeMergeRetry),
+			)
+		}
+	}
+
+	if !config.LargeValueBytes.IsNull() && !config.LargeValueBytes.IsUnknown() && config.LargeValueBytes.ValueInt64() < 0 {
+		resp.Diagnostics.AddAttributeError(
+			tfpath.Root("large_value_bytes"),
+			"Invalid Large Value Bytes",
+			fmt.Sprintf("Must not be negative, got %d.", config.LargeValueBytes.ValueInt64()),
+		)
+	}
+
+	if !config.VersionPressureMargin.IsNull() && !config.VersionPressureMargin.IsUnknown() && config.VersionPressureMargin.ValueInt64() < 0 {
+		resp.Diagnostics.AddAttributeError(
+			tfpath.Root("version_pressure_margin"),
+			"Invalid Version Pressure Margin",
+			fmt.Sprintf("Must not be negative, got %d.", config.VersionPressureMargin.ValueInt64()),
+		)
+	}
+
+	if !config.OnConcurrentChange.IsNull() && !config.OnConcurrentChange.IsUnknown() {
+		switch config.OnConcurrentChange.ValueString() {
+		case concurrentChangeOverwrite, concurrentChangeError, concurrentChangeMergeRetry:
+		default:
+			resp.Diagnostics.AddAttributeError(
+				tfpath.Root("on_concurrent_change"),
+				"Invalid On Concurrent Change",
+				fmt.Sprintf("Must be one of %q, %q or %q, got %q.",
+					concurrentChangeOverwrite, concurrentChangeError, concurrentChangeMergeRetry,
+					config.OnConcurrentChange.ValueString()),
+			)
+		}
+	}
+}
+
+func (r *KvKeysResource) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	if req.Plan.Raw.IsNull() {
+		return
+	}
+
+	var config KvKeysResourceModel
+	resp.Diagnostics.Append(req.Config.Get(ctx, &config)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	keys := config.Keys
+	if !config.WorkspaceKeys.IsNull() {
+		if config.WorkspaceKeys.IsUnknown() || config.Workspace.IsUnknown() {
+			keys = types.MapUnknown(types.StringType)
+		} else {
+			selected, ok := selectWorkspaceKeys(config.WorkspaceKeys, config.Workspace.ValueString())
+			if !ok {
+				resp.Diagnostics.AddAttributeError(
+					tfpath.Root("workspace_keys"),
+					"No Keys for Workspace",
+					fmt.Sprintf("'workspace_keys' has no entry for workspace %q and no 'default' entry. Available entries: %s.",
+						config.Workspace.ValueString(), strings.Join(sortedMapKeys(config.WorkspaceKeys), ", ")),
+				)
+				return
+			}
+			keys = selected
+		}
+		resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, tfpath.Root("keys"), keys)...)
+	}
+
+	summaries := types.MapNull(types.StringType)
+	if config.SummarizeLargeValues.ValueBool() {
+		if !mapKnown(keys) || config.LargeValueBytes.IsUnknown() {
+			summaries = types.MapUnknown(types.StringType)
+		} else {
+			values, _ := splitKeys(keys)
+			summaries = largeValueSummaries(config, values)
+		}
+	}
+	resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, tfpath.Root("large_value_summaries"), summaries)...)
+
+	if !req.State.Raw.IsNull() && !config.PinnedVersion.IsNull() && !config.PinnedVersion.IsUnknown() {
+		var current types.Int64
+		resp.Diagnostics.Append(req.State.GetAttribute(ctx, tfpath.Root("current_version"), &current)...)
+		if pin := config.PinnedVersion.ValueInt64(); current.ValueInt64() > pin {
+			resp.Diagnostics.AddAttributeWarning(
+				tfpath.Root("pinned_version"),
+				"Secret Version Advanced Beyond Pin",
+				fmt.Sprintf("%s/%s is at version %d but pinned_version is %d. Review the newer versions, then "+
+					"update pinned_version to %d to stop this warning.",
+					secretMount(config), secretPath(config), current.ValueInt64(), pin, current.ValueInt64()),
+			)
+		}
+	}
+}
+
 func (r *KvKeysResource) Configure(_ context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
 	if req.ProviderData == nil {
 		return
@@ -84,28 +844,71 @@ func (r *KvKeysResource) Configure(_ context.Context, req resource.ConfigureRequ
 }
 
 func (r *KvKeysResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	if r.client.ReadOnly {
+		addReadOnlyError(&resp.Diagnostics)
+		return
+	}
+
 	var plan KvKeysResourceModel
 	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
 	if resp.Diagnostics.HasError() {
 		return
 	}
 
-	mount := plan.Mount.ValueString()
-	path := plan.Path.ValueString()
+	mount := secretMount(plan)
+	path := secretPath(plan)
+	client := r.clientFor(plan)
+
+	planKeys, nullKeys := splitKeys(plan.Keys)
+	if len(nullKeys) > 0 && !plan.NullMeansDelete.ValueBool() {
+		resp.Diagnostics.AddError("Null Value in Keys", nullKeysDetail(nullKeys))
+		return
+	}
+	resp.Diagnostics.Append(checkJSONSchemas(ctx, plan.JSONSchema, planKeys)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	transforms, diags := keyTransforms(ctx, plan.Transforms)
+	resp.Diagnostics.Append(diags...)
+	planPointers, diags := jsonPointerFields(ctx, plan.JSONPointers)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	writeKeys := transformKeys(planKeys, transforms)
+	ctx = r.client.maskValues(ctx, planKeys, writeKeys)
 
-	planKeys := make(map[string]string)
-	resp.Diagnostics.Append(plan.Keys.ElementsAs(ctx, &planKeys, false)...)
+	writeCommand, _ := r.valueCommands(ctx, plan, &resp.Diagnostics)
 	if resp.Diagnostics.HasError() {
 		return
 	}
+	if writeCommand != nil {
+		converted, err := commandKeys(ctx, writeCommand, writeKeys, writeKeys)
+		if err != nil {
+			resp.Diagnostics.AddError("Value Command Failed", err.Error())
+			return
+		}
+		writeKeys = converted
+	}
 
-	tflog.Info(ctx, "Creating keys in Vault", map[string]interface{}{
+	r.client.logOperation(ctx, "Creating keys in Vault", map[string]interface{}{
 		"mount": mount,
 		"path":  path,
 		"keys":  keysOnly(planKeys),
 	})
 
-	existingData, err := r.readSecret(mount, path)
+	if plan.UndeleteFirst.ValueBool() {
+		if err := restoreSoftDeleted(ctx, client, mount, path); err != nil {
+			resp.Diagnostics.AddError(
+				"Failed to Undelete Secret",
+				fmt.Sprintf("Could not restore the soft-deleted latest version of %s/%s: %s", mount, path, err),
+			)
+			return
+		}
+	}
+
+	existingData, version, err := client.readSecretVersion(ctx, mount, path)
 	if err != nil {
 		resp.Diagnostics.AddError(
 			"Failed to Read Existing Secret",
@@ -114,24 +917,60 @@ func (r *KvKeysResource) Create(ctx context.Context, req resource.CreateRequest,
 		return
 	}
 
-	if !keysMatch(existingData, planKeys) {
-		merged := mergeKeys(existingData, planKeys)
+	merged := mergeKeys(existingData, writeKeys)
+	for _, key := range nullKeys {
+		delete(merged, key)
+	}
+	if err := applyJSONPointers(merged, planPointers, nil, jsonEncodingFor(plan)); err != nil {
+		resp.Diagnostics.AddAttributeError(
+			tfpath.Root("json_pointers"),
+			"Failed to Update Structured Key",
+			err.Error(),
+		)
+		return
+	}
 
-		if err := r.writeSecret(mount, path, merged); err != nil {
+	if !keysMatch(existingData, merged) || len(existingData) != len(merged) {
+		written, err := client.writeSecret(ctx, mount, path, merged)
+		if err != nil {
 			resp.Diagnostics.AddError(
-				"Failed to Write Secret",
+				writeErrorSummary(err),
 				fmt.Sprintf("Could not write to %s/%s: %s", mount, path, err),
 			)
 			return
 		}
+		if r.client.DryRun {
+			addDryRunWarning(&resp.Diagnostics, mount, path)
+			plan.LastWritten = types.StringNull()
+		} else {
+			version = written
+			if plan.VerifyKeyCount.ValueBool() {
+				if err := verifyKeyCount(ctx, client, mount, path, merged, version); err != nil {
+					resp.Diagnostics.AddError("Keys Lost in Write", err.Error())
+					return
+				}
+			}
+			plan.LastWritten = types.StringValue(time.Now().UTC().Format(time.RFC3339))
+			backupKeys(plan, planKeys, version, &resp.Diagnostics)
+			checkVersionPressure(ctx, client, plan, &resp.Diagnostics)
+		}
 	} else {
-		tflog.Info(ctx, "All keys already exist with the same values, skipping write", map[string]interface{}{
+		r.client.logOperation(ctx, "All keys already exist with the same values, skipping write", map[string]interface{}{
 			"mount": mount,
 			"path":  path,
 		})
+		plan.LastWritten = types.StringNull()
+		if len(client.CustomMetadata) > 0 && !r.client.DryRun {
+			applyCustomMetadata(ctx, client, mount, path, &resp.Diagnostics)
+		}
 	}
 
-	plan.ID = types.StringValue(fmt.Sprintf("%s/%s", mount, path))
+	plan.ID = types.StringValue(r.resourceID(plan))
+	plan.MountAccessor = r.resolveMountAccessor(ctx, client, mount)
+	plan.CurrentVersion = types.Int64Value(version)
+	plan.KeysChecksum = types.StringValue(keysChecksum(planKeys))
+	plan.ValueSummaries = largeValueSummaries(plan, planKeys)
+	pinVersion(&plan, version, &resp.Diagnostics)
 	resp.Diagnostics.Append(resp.State.Set(ctx, &plan)...)
 }
 
@@ -142,22 +981,40 @@ func (r *KvKeysResource) Read(ctx context.Context, req resource.ReadRequest, res
 		return
 	}
 
-	mount := state.Mount.ValueString()
-	path := state.Path.ValueString()
+	mount := secretMount(state)
+	path := secretPath(state)
+	client := r.clientFor(state)
 
-	stateKeys := make(map[string]string)
-	resp.Diagnostics.Append(state.Keys.ElementsAs(ctx, &stateKeys, false)...)
+	stateKeys, nullKeys := splitKeys(state.Keys)
+	transforms, diags := keyTransforms(ctx, state.Transforms)
+	resp.Diagnostics.Append(diags...)
 	if resp.Diagnostics.HasError() {
 		return
 	}
+	ctx = r.client.maskValues(ctx, stateKeys, transformKeys(stateKeys, transforms))
 
-	tflog.Info(ctx, "Reading keys from Vault", map[string]interface{}{
+	r.client.logOperation(ctx, "Reading keys from Vault", map[string]interface{}{
 		"mount": mount,
 		"path":  path,
 	})
 
-	existingData, err := r.readSecret(mount, path)
+	existingData, version, err := client.readSecretVersion(ctx, mount, path)
 	if err != nil {
+		if errors.Is(err, errMountMissing) {
+			resp.Diagnostics.AddError(
+				"Mount Not Found",
+				fmt.Sprintf("Vault has no secrets engine at %q: the mount appears to have been disabled or moved. "+
+					"The resource was kept in state; re-enable the mount or update 'mount' to its new path.", mount),
+			)
+			return
+		}
+		if state.DetectOnly.ValueBool() {
+			resp.Diagnostics.AddError(
+				"Failed to Read Secret",
+				fmt.Sprintf("Could not read %s/%s for drift detection: %s", mount, path, err),
+			)
+			return
+		}
 		tflog.Warn(ctx, "Could not read secret from Vault, removing from state", map[string]interface{}{
 			"error": err.Error(),
 		})
@@ -165,41 +1022,153 @@ func (r *KvKeysResource) Read(ctx context.Context, req resource.ReadRequest, res
 		return
 	}
 
-	currentKeys := make(map[string]string)
-	for key := range stateKeys {
+	_, readCommand := r.valueCommands(ctx, state, &resp.Diagnostics)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	if readCommand != nil {
+		decoded, err := commandKeys(ctx, readCommand, existingData, stateKeys)
+		if err != nil {
+			resp.Diagnostics.AddError("Value Read Command Failed", err.Error())
+			return
+		}
+		existingData = decoded
+	}
+
+	drifted := driftedKeys(existingData, transformKeys(stateKeys, transforms), nullKeys, knownStrings(state.ValueTypes))
+	if state.DetectOnly.ValueBool() {
+		if len(drifted) > 0 {
+			resp.Diagnostics.AddError(
+				"Out-of-Band Change Detected",
+				fmt.Sprintf("The following managed keys in %s/%s no longer match the last applied state: %s. "+
+					"detect_only is enabled, so state was not reconciled. Investigate the change, then restore the "+
+					"values in Vault or disable detect_only to accept it.",
+					mount, path, strings.Join(drifted, ", ")),
+			)
+			return
+		}
+	}
+
+	// A soft-deleted latest version reads as an empty secret. Rather than
+	// dropping the resource, report it, or with undelete_before_write let
+	// the keys show as missing so the next apply undeletes and restores them.
+	restoreOnApply := false
+	if len(existingData) == 0 && len(stateKeys) > 0 && !client.kvV1() {
+		if deleted, ok := softDeletedVersion(ctx, client, mount, path); ok {
+			if !state.UndeleteFirst.ValueBool() {
+				resp.Diagnostics.AddError(
+					"Latest Secret Version Soft-Deleted",
+					fmt.Sprintf("Version %d of %s/%s, the latest, was deleted outside Terraform. The resource was kept "+
+						"in state. Undelete it (vault kv undelete -versions=%d), set undelete_before_write = true to "+
+						"restore it on the next apply, or remove the resource from state.",
+						deleted, mount, path, deleted),
+				)
+				return
+			}
+			resp.Diagnostics.AddWarning(
+				"Latest Secret Version Soft-Deleted",
+				fmt.Sprintf("Version %d of %s/%s, the latest, was deleted outside Terraform. The next apply undeletes "+
+					"it and restores the managed keys.", deleted, mount, path),
+			)
+			restoreOnApply = true
+		}
+	}
+
+	absentAsNull := state.AbsentKeysNull.ValueBool()
+	currentKeys := make(map[string]attr.Value)
+	valueTypes := knownStrings(state.ValueTypes)
+	for key, configured := range stateKeys {
 		if val, exists := existingData[key]; exists {
-			currentKeys[key] = val
+			if valueTypes[key] != "" && sameTypedValue(valueTypes[key], configured, val) {
+				val = configured
+			}
+			currentKeys[key] = types.StringValue(untransformedValue(transforms[key], configured, val))
+		} else if absentAsNull {
+			currentKeys[key] = types.StringNull()
 		}
 	}
 
-	if len(currentKeys) == 0 {
+	if len(stateKeys) > 0 && len(currentKeys) == 0 && !restoreOnApply {
 		tflog.Warn(ctx, "None of the managed keys exist in Vault, removing from state")
 		resp.State.RemoveResource(ctx)
 		return
 	}
 
-	keysMapValue, diags := types.MapValueFrom(ctx, types.StringType, currentKeys)
+	// Keys configured as null stay null while absent, so a reappearing key
+	// shows up as drift and is deleted again on the next apply.
+	for _, key := range nullKeys {
+		if val, exists := existingData[key]; exists {
+			currentKeys[key] = types.StringValue(val)
+		} else {
+			currentKeys[key] = types.StringNull()
+		}
+	}
+
+	keysMapValue, diags := types.MapValue(types.StringType, currentKeys)
 	resp.Diagnostics.Append(diags...)
 	if resp.Diagnostics.HasError() {
 		return
 	}
 
 	state.Keys = keysMapValue
+	refreshedKeys, _ := splitKeys(keysMapValue)
+	state.KeysChecksum = types.StringValue(keysChecksum(refreshedKeys))
+	state.ValueSummaries = largeValueSummaries(state, refreshedKeys)
+
+	if !state.JSONPointers.IsNull() {
+		statePointers, diags := jsonPointerFields(ctx, state.JSONPointers)
+		resp.Diagnostics.Append(diags...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+
+		pointersValue, diags := types.MapValueFrom(ctx, types.MapType{ElemType: types.StringType},
+			currentJSONPointers(existingData, statePointers))
+		resp.Diagnostics.Append(diags...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+		state.JSONPointers = pointersValue
+	}
+
+	state.MountAccessor = r.resolveMountAccessor(ctx, client, mount)
+	state.CurrentVersion = types.Int64Value(version)
+
+	// Versions that only touch other keys are expected on shared paths; a
+	// pin set in configuration is checked in ModifyPlan instead.
+	if !state.PinnedVersion.IsNull() && version > state.PinnedVersion.ValueInt64() && len(drifted) > 0 {
+		resp.Diagnostics.AddWarning(
+			"Secret Version Advanced Beyond Pin",
+			fmt.Sprintf("%s/%s is at version %d but this resource is pinned to version %d, and another writer "+
+				"has changed these managed keys since they were last managed here: %s.",
+				mount, path, version, state.PinnedVersion.ValueInt64(), strings.Join(drifted, ", ")),
+		)
+	}
 	resp.Diagnostics.Append(resp.State.Set(ctx, &state)...)
 }
 
 func (r *KvKeysResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	if r.client.ReadOnly {
+		addReadOnlyError(&resp.Diagnostics)
+		return
+	}
+
 	var plan KvKeysResourceModel
 	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
 	if resp.Diagnostics.HasError() {
 		return
 	}
 
-	mount := plan.Mount.ValueString()
-	path := plan.Path.ValueString()
+	mount := secretMount(plan)
+	path := secretPath(plan)
+	client := r.clientFor(plan)
 
-	planKeys := make(map[string]string)
-	resp.Diagnostics.Append(plan.Keys.ElementsAs(ctx, &planKeys, false)...)
+	planKeys, nullKeys := splitKeys(plan.Keys)
+	if len(nullKeys) > 0 && !plan.NullMeansDelete.ValueBool() {
+		resp.Diagnostics.AddError("Null Value in Keys", nullKeysDetail(nullKeys))
+		return
+	}
+	resp.Diagnostics.Append(checkJSONSchemas(ctx, plan.JSONSchema, planKeys)...)
 	if resp.Diagnostics.HasError() {
 		return
 	}
@@ -210,70 +1179,211 @@ func (r *KvKeysResource) Update(ctx context.Context, req resource.UpdateRequest,
 		return
 	}
 
-	stateKeys := make(map[string]string)
-	resp.Diagnostics.Append(state.Keys.ElementsAs(ctx, &stateKeys, false)...)
+	stateKeys, stateNullKeys := splitKeys(state.Keys)
+	transforms, diags := keyTransforms(ctx, plan.Transforms)
+	resp.Diagnostics.Append(diags...)
 	if resp.Diagnostics.HasError() {
 		return
 	}
+	writeKeys := transformKeys(planKeys, transforms)
+	ctx = r.client.maskValues(ctx, planKeys, writeKeys, stateKeys)
 
-	tflog.Info(ctx, "Updating keys in Vault", map[string]interface{}{
-		"mount": mount,
-		"path":  path,
-		"keys":  keysOnly(planKeys),
-	})
-
-	existingData, err := r.readSecret(mount, path)
-	if err != nil {
-		resp.Diagnostics.AddError(
-			"Failed to Read Existing Secret",
-			fmt.Sprintf("Could not read %s/%s: %s", mount, path, err),
-		)
+	writeCommand, _ := r.valueCommands(ctx, plan, &resp.Diagnostics)
+	if resp.Diagnostics.HasError() {
 		return
 	}
-
-	for key := range stateKeys {
-		if _, existsInPlan := planKeys[key]; !existsInPlan {
-			delete(existingData, key)
+	if writeCommand != nil {
+		converted, err := commandKeys(ctx, writeCommand, writeKeys, writeKeys)
+		if err != nil {
+			resp.Diagnostics.AddError("Value Command Failed", err.Error())
+			return
 		}
+		writeKeys = converted
+	}
+	for _, key := range stateNullKeys {
+		stateKeys[key] = ""
 	}
 
-	merged := mergeKeys(existingData, planKeys)
-
-	if err := r.writeSecret(mount, path, merged); err != nil {
-		resp.Diagnostics.AddError(
-			"Failed to Write Secret",
-			fmt.Sprintf("Could not write to %s/%s: %s", mount, path, err),
-		)
+	planPointers, diags := jsonPointerFields(ctx, plan.JSONPointers)
+	resp.Diagnostics.Append(diags...)
+	statePointers, diags := jsonPointerFields(ctx, state.JSONPointers)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
 		return
 	}
+	removedPointers := pointerNames(statePointers)
+	for key, pointers := range removedPointers {
+		kept := pointers[:0]
+		for _, pointer := range pointers {
+			if _, stillManaged := planPointers[key][pointer]; !stillManaged {
+				kept = append(kept, pointer)
+			}
+		}
+		removedPointers[key] = kept
+	}
+
+	r.client.logOperation(ctx, "Updating keys in Vault", map[string]interface{}{
+		"mount": mount,
+		"path":  path,
+		"keys":  keysOnly(planKeys),
+	})
 
-	plan.ID = types.StringValue(fmt.Sprintf("%s/%s", mount, path))
+	if plan.UndeleteFirst.ValueBool() {
+		if err := restoreSoftDeleted(ctx, client, mount, path); err != nil {
+			resp.Diagnostics.AddError(
+				"Failed to Undelete Secret",
+				fmt.Sprintf("Could not restore the soft-deleted latest version of %s/%s: %s", mount, path, err),
+			)
+			return
+		}
+	}
+
+	existingData, version, err := client.readSecretVersion(ctx, mount, path)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Failed to Read Existing Secret",
+			fmt.Sprintf("Could not read %s/%s: %s", mount, path, err),
+		)
+		return
+	}
+
+	// Keep the pin the user configured; an unconfigured pin follows our writes.
+	var config KvKeysResourceModel
+	resp.Diagnostics.Append(req.Config.Get(ctx, &config)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	plan.PinnedVersion = config.PinnedVersion
+
+	onChange := plan.OnConcurrentChange.ValueString()
+	if !state.CurrentVersion.IsNull() && version > state.CurrentVersion.ValueInt64() {
+		if onChange == concurrentChangeError {
+			resp.Diagnostics.AddError(
+				"Concurrent Modification Detected",
+				fmt.Sprintf("%s/%s is at version %d, but state recorded version %d. Another writer changed the secret "+
+					"since it was last read; refresh and review the plan before applying again.",
+					mount, path, version, state.CurrentVersion.ValueInt64()),
+			)
+			return
+		}
+		tflog.Warn(ctx, "Secret changed since last read, merging onto the latest version", map[string]interface{}{
+			"mount":         mount,
+			"path":          path,
+			"state_version": state.CurrentVersion.ValueInt64(),
+			"live_version":  version,
+		})
+	}
+
+	var pointerErr error
+	var lastMerged map[string]string
+	merge := func(existing map[string]string) map[string]string {
+		for key := range stateKeys {
+			if _, existsInPlan := planKeys[key]; !existsInPlan {
+				delete(existing, key)
+			}
+		}
+		for _, key := range nullKeys {
+			delete(existing, key)
+		}
+		merged := mergeKeys(existing, writeKeys)
+		if err := applyJSONPointers(merged, planPointers, removedPointers, jsonEncodingFor(plan)); err != nil {
+			pointerErr = err
+		}
+		lastMerged = merged
+		return merged
+	}
+
+	// Surface JSON pointer errors before anything is written.
+	preview := merge(mergeKeys(existingData, nil))
+	if pointerErr != nil {
+		resp.Diagnostics.AddAttributeError(
+			tfpath.Root("json_pointers"),
+			"Failed to Update Structured Key",
+			pointerErr.Error(),
+		)
+		return
+	}
+
+	var written int64
+	deletedEmpty := len(preview) == 0 && !allowEmptySecret(plan)
+	if deletedEmpty {
+		written = version
+		err = deleteEmptySecret(ctx, client, mount, path)
+	} else if onChange == concurrentChangeMergeRetry {
+		retries, backoff := casRetries(plan, client.Backoff)
+		written, err = client.writeSecretMerged(ctx, mount, path, existingData, version, merge, retries, backoff)
+	} else {
+		written, err = client.writeSecret(ctx, mount, path, merge(existingData))
+	}
+	if err != nil {
+		resp.Diagnostics.AddError(
+			writeErrorSummary(err),
+			fmt.Sprintf("Could not write to %s/%s: %s", mount, path, err),
+		)
+		return
+	}
+	if r.client.DryRun {
+		addDryRunWarning(&resp.Diagnostics, mount, path)
+		plan.LastWritten = state.LastWritten
+	} else {
+		version = written
+		if plan.VerifyKeyCount.ValueBool() && !deletedEmpty {
+			if err := verifyKeyCount(ctx, client, mount, path, lastMerged, version); err != nil {
+				resp.Diagnostics.AddError("Keys Lost in Write", err.Error())
+				return
+			}
+		}
+		plan.LastWritten = types.StringValue(time.Now().UTC().Format(time.RFC3339))
+		backupKeys(plan, planKeys, version, &resp.Diagnostics)
+		if !deletedEmpty {
+			checkVersionPressure(ctx, client, plan, &resp.Diagnostics)
+		}
+	}
+
+	// Writes set custom_metadata, but a content-addressed write may have been
+	// skipped; this is a no-op when the entries are already in place.
+	if !plan.CustomMetadata.Equal(state.CustomMetadata) && len(client.CustomMetadata) > 0 && !r.client.DryRun && !deletedEmpty {
+		applyCustomMetadata(ctx, client, mount, path, &resp.Diagnostics)
+	}
+
+	plan.ID = types.StringValue(r.resourceID(plan))
+	plan.MountAccessor = r.resolveMountAccessor(ctx, client, mount)
+	plan.CurrentVersion = types.Int64Value(version)
+	plan.KeysChecksum = types.StringValue(keysChecksum(planKeys))
+	plan.ValueSummaries = largeValueSummaries(plan, planKeys)
+	pinVersion(&plan, version, &resp.Diagnostics)
 	resp.Diagnostics.Append(resp.State.Set(ctx, &plan)...)
 }
 
 func (r *KvKeysResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	if r.client.ReadOnly {
+		addReadOnlyError(&resp.Diagnostics)
+		return
+	}
+
 	var state KvKeysResourceModel
 	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
 	if resp.Diagnostics.HasError() {
 		return
 	}
 
-	mount := state.Mount.ValueString()
-	path := state.Path.ValueString()
+	mount := secretMount(state)
+	path := secretPath(state)
+	client := r.clientFor(state)
 
-	stateKeys := make(map[string]string)
-	resp.Diagnostics.Append(state.Keys.ElementsAs(ctx, &stateKeys, false)...)
-	if resp.Diagnostics.HasError() {
-		return
+	stateKeys, nullKeys := splitKeys(state.Keys)
+	ctx = r.client.maskValues(ctx, stateKeys)
+	for _, key := range nullKeys {
+		stateKeys[key] = ""
 	}
 
-	tflog.Info(ctx, "Deleting keys from Vault", map[string]interface{}{
+	r.client.logOperation(ctx, "Deleting keys from Vault", map[string]interface{}{
 		"mount": mount,
 		"path":  path,
 		"keys":  keysOnly(stateKeys),
 	})
 
-	existingData, err := r.readSecret(mount, path)
+	existingData, err := client.readSecret(ctx, mount, path)
 	if err != nil {
 		tflog.Warn(ctx, "Could not read secret during delete, assuming already cleaned up", map[string]interface{}{
 			"error": err.Error(),
@@ -285,18 +1395,101 @@ func (r *KvKeysResource) Delete(ctx context.Context, req resource.DeleteRequest,
 		delete(existingData, key)
 	}
 
-	if err := r.writeSecret(mount, path, existingData); err != nil {
+	statePointers, diags := jsonPointerFields(ctx, state.JSONPointers)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	if err := applyJSONPointers(existingData, nil, pointerNames(statePointers), jsonEncodingFor(state)); err != nil {
+		resp.Diagnostics.AddError(
+			"Failed to Update Structured Key",
+			fmt.Sprintf("Could not remove managed fields from %s/%s: %s", mount, path, err),
+		)
+		return
+	}
+
+	if len(existingData) == 0 && !allowEmptySecret(state) {
+		err = deleteEmptySecret(ctx, client, mount, path)
+	} else {
+		_, err = client.writeSecret(ctx, mount, path, existingData)
+	}
+	if err != nil {
 		resp.Diagnostics.AddError(
 			"Failed to Write Secret After Delete",
 			fmt.Sprintf("Could not update %s/%s after removing keys: %s", mount, path, err),
 		)
 		return
 	}
+	if r.client.DryRun {
+		addDryRunWarning(&resp.Diagnostics, mount, path)
+	}
 }
 
 func (r *KvKeysResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
 	id := req.ID
 
+	// A trailing '@' followed only by digits pins a version; any other '@'
+	// is part of the path (e.g. 'users/alice@example.com').
+	var pinned *int64
+	if at := strings.LastIndex(id, "@"); at > 0 && isDigits(id[at+1:]) {
+		version, err := strconv.ParseInt(id[at+1:], 10, 64)
+		if err != nil || version < 1 {
+			resp.Diagnostics.AddError(
+				"Invalid Import ID",
+				"The version after '@' must be a positive integer (e.g., 'app_envs/my-service/test@3').",
+			)
+			return
+		}
+		pinned = &version
+		id = id[:at]
+	}
+
+	// The address is matched as a whole, since it may have a path of its own
+	// (e.g. 'https://example.com/vault').
+	idFormat := types.StringNull()
+	if rest, ok := strings.CutPrefix(id, r.client.Address+"/"); ok {
+		id = rest
+		idFormat = types.StringValue(idFormatAddressMountPath)
+	} else if strings.Contains(id, "://") {
+		resp.Diagnostics.AddError(
+			"Import ID Targets Another Vault",
+			fmt.Sprintf("The import ID %q does not start with the provider address %s.", id, r.client.Address),
+		)
+		return
+	}
+
+	// Only an explicit 'ns:<namespace>:' prefix names a namespace, so ':' in
+	// a mount or path (e.g. 'app/db:primary') is read as part of it.
+	namespace := types.StringNull()
+	if rest, ok := strings.CutPrefix(id, namespaceIDPrefix); ok {
+		ns, rest, ok := strings.Cut(rest, ":")
+		ns = strings.Trim(ns, "/")
+		if !ok || ns == "" {
+			resp.Diagnostics.AddError(
+				"Invalid Import ID",
+				"An import ID starting with 'ns:' must name a namespace followed by ':' "+
+					"(e.g., 'ns:team-a:app_envs/my-service/test').",
+			)
+			return
+		}
+		namespace = types.StringValue(ns)
+		id = rest
+		// The provider's own namespace is only in the ID for
+		// '{namespace}/{mount}/{path}'; the resource does not override it.
+		if ns == strings.Trim(r.client.Namespace, "/") && idFormat.IsNull() {
+			namespace = types.StringNull()
+			idFormat = types.StringValue(idFormatNamespaceMountPath)
+		}
+	}
+
+	// The ID is cleaned like a configured mount and path, so an imported
+	// resource gets the same ID and state as one created from configuration.
+	id, err := cleanSecretPath(id)
+	if err != nil {
+		resp.Diagnostics.AddError("Invalid Import ID", err.Error())
+		return
+	}
+
 	idx := strings.Index(id, "/")
 	if idx < 0 {
 		resp.Diagnostics.AddError(
@@ -309,15 +1502,26 @@ func (r *KvKeysResource) ImportState(ctx context.Context, req resource.ImportSta
 	mount := id[:idx]
 	path := id[idx+1:]
 
-	if mount == "" || path == "" {
+	scoped := r.clientFor(KvKeysResourceModel{Namespace: namespace})
+	kvVersion, err := scoped.mountKVVersion(ctx, mount)
+	if err != nil {
+		resp.Diagnostics.AddWarning(
+			"Could Not Detect KV Version",
+			fmt.Sprintf("Could not read the %s mount from sys/mounts (%s); importing it as KV v2. "+
+				"Set kv_version = 1 in the configuration if it is a KV v1 mount.", mount, err),
+		)
+		kvVersion = 2
+	}
+	if kvVersion == 1 && pinned != nil {
 		resp.Diagnostics.AddError(
 			"Invalid Import ID",
-			"Both mount and path must be non-empty. Format: 'mount/path'.",
+			fmt.Sprintf("%s is a KV v1 mount, which has no versions to pin with '@'.", mount),
 		)
 		return
 	}
+	client := scoped.withKVVersion(kvVersion)
 
-	existingData, err := r.readSecret(mount, path)
+	existingData, version, err := client.readSecretVersion(ctx, mount, path)
 	if err != nil {
 		resp.Diagnostics.AddError(
 			"Failed to Read Secret During Import",
@@ -333,97 +1537,670 @@ func (r *KvKeysResource) ImportState(ctx context.Context, req resource.ImportSta
 	}
 
 	state := KvKeysResourceModel{
-		ID:    types.StringValue(id),
 		Mount: types.StringValue(mount),
 		Path:  types.StringValue(path),
 		Keys:  keysMapValue,
+
+		PinnedVersion: types.Int64Value(version),
+		KVVersion:     types.Int64Value(int64(kvVersion)),
+		IDFormat:      idFormat,
+		Namespace:     namespace,
+
+		MountAccessor:  r.resolveMountAccessor(ctx, client, mount),
+		CurrentVersion: types.Int64Value(version),
+		LastWritten:    types.StringNull(),
+		KeysChecksum:   types.StringValue(keysChecksum(existingData)),
+		ValueSummaries: types.MapNull(types.StringType),
+
+		WorkspaceKeys:    types.MapNull(types.MapType{ElemType: types.StringType}),
+		JSONPointers:     types.MapNull(types.MapType{ElemType: types.StringType}),
+		Transforms:       types.MapNull(types.StringType),
+		ValueTypes:       types.MapNull(types.StringType),
+		ValueCommand:     types.ListNull(types.StringType),
+		ValueReadCommand: types.ListNull(types.StringType),
+		JSONSchema:       types.MapNull(types.StringType),
+		Headers:          types.MapNull(types.StringType),
+		CustomMetadata:   types.MapNull(types.StringType),
 	}
+	if pinned != nil {
+		state.PinnedVersion = types.Int64Value(*pinned)
+	}
+	state.ID = types.StringValue(r.resourceID(state))
 
 	resp.Diagnostics.Append(resp.State.Set(ctx, &state)...)
 }
 
-func (r *KvKeysResource) readSecret(mount, path string) (map[string]string, error) {
-	url := fmt.Sprintf("%s/v1/%s/data/%s", r.client.Address, mount, path)
+// clientFor returns the provider client scoped to the resource's settings.
+func (r *KvKeysResource) clientFor(model KvKeysResourceModel) *VaultClient {
+	version := int(model.KVVersion.ValueInt64())
+	if model.KVVersion.IsNull() || model.KVVersion.IsUnknown() {
+		version = 2
+	}
+	client := r.client.withKVVersion(version)
 
-	req, err := http.NewRequest("GET", url, nil)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create request: %w", err)
+	if headers := knownStrings(model.Headers); len(headers) > 0 {
+		client = client.withHeaders(headers)
+	}
+	if flatten := model.FlattenUnder.ValueString(); flatten != "" {
+		client = client.withFlattenUnder(flatten)
+	}
+	if valueTypes := knownStrings(model.ValueTypes); len(valueTypes) > 0 {
+		client = client.withValueTypes(valueTypes)
+	}
+	if custom := knownStrings(model.CustomMetadata); len(custom) > 0 {
+		client = client.withCustomMetadata(custom)
 	}
-	req.Header.Set("X-Vault-Token", r.client.Token)
-	req.Header.Set("X-Vault-Request", "true")
+	if namespace := strings.Trim(model.Namespace.ValueString(), "/"); namespace != "" {
+		client = client.withNamespace(namespace)
+	}
+	return client
+}
 
-	resp, err := r.client.HTTPClient.Do(req)
-	if err != nil {
-		return nil, fmt.Errorf("failed to send request: %w", err)
+// knownStrings returns the known, non-null elements of a string map.
+func knownStrings(m types.Map) map[string]string {
+	result := make(map[string]string)
+	for name, value := range m.Elements() {
+		if str, ok := value.(types.String); ok && !str.IsNull() && !str.IsUnknown() {
+			result[name] = str.ValueString()
+		}
 	}
-	defer resp.Body.Close()
+	return result
+}
 
-	body, err := io.ReadAll(resp.Body)
+func (r *KvKeysResource) resolveMountAccessor(ctx context.Context, client *VaultClient, mount string) types.String {
+	accessor, err := client.mountAccessor(ctx, mount)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read response: %w", err)
+		tflog.Warn(ctx, "Could not resolve mount accessor, leaving it unset", map[string]interface{}{
+			"mount": mount,
+			"error": err.Error(),
+		})
+		return types.StringNull()
 	}
+	return types.StringValue(accessor)
+}
 
-	if resp.StatusCode == http.StatusNotFound {
-		return make(map[string]string), nil
+// pinVersion moves an unconfigured pin to the version just written. A pin
+// set in configuration is left alone, with a warning once our own write has
+// moved the secret past it.
+func pinVersion(model *KvKeysResourceModel, version int64, diags *diag.Diagnostics) {
+	if model.PinnedVersion.IsNull() || model.PinnedVersion.IsUnknown() {
+		model.PinnedVersion = types.Int64Value(version)
+		return
 	}
 
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("vault returned status %d: %s", resp.StatusCode, string(body))
+	if version > model.PinnedVersion.ValueInt64() {
+		diags.AddWarning(
+			"Write Advanced Secret Beyond Pin",
+			fmt.Sprintf("This apply created version %d, which is newer than the configured pinned_version %d. "+
+				"Update pinned_version to %d to stop the plan warning.",
+				version, model.PinnedVersion.ValueInt64(), version),
+		)
 	}
+}
+
+const (
+	concurrentChangeOverwrite  = "overwrite"
+	concurrentChangeError      = "error"
+	concurrentChangeMergeRetry = "merge-retry"
+
+	templateMarkersError = "error"
+	templateMarkersWarn  = "warn"
+
+	trailingSlashError = "error"
+	trailingSlashStrip = "strip"
+
+	reservedKeyNamesWarn  = "warn"
+	reservedKeyNamesError = "error"
+	reservedKeyNamesAllow = "allow"
+
+	// casMaxRetries bounds how often merge-retry re-merges after losing a
+	// check-and-set race, unless cas_max_retries is set.
+	casMaxRetries = 3
+)
 
-	var result struct {
-		Data struct {
-			Data map[string]interface{} `json:"data"`
-		} `json:"data"`
+// reservedKeyNames are the fields of the KV v2 envelope that a key name can
+// be mistaken for.
+var reservedKeyNames = []string{"data", "metadata", "options"}
+
+// reservedKeyNamesIn returns the reserved names used as keys in config,
+// across 'keys' and every 'workspace_keys' entry.
+func reservedKeyNamesIn(config KvKeysResourceModel) []string {
+	maps := []types.Map{config.Keys}
+	if !config.WorkspaceKeys.IsUnknown() {
+		for _, elem := range config.WorkspaceKeys.Elements() {
+			if keys, ok := elem.(types.Map); ok {
+				maps = append(maps, keys)
+			}
+		}
 	}
 
-	if err := json.Unmarshal(body, &result); err != nil {
-		return nil, fmt.Errorf("failed to parse response: %w", err)
+	var found []string
+	for _, name := range reservedKeyNames {
+		for _, keys := range maps {
+			if _, ok := keys.Elements()[name]; ok {
+				found = append(found, name)
+				break
+			}
+		}
 	}
+	return found
+}
 
-	if result.Data.Data == nil {
-		return make(map[string]string), nil
+// isDigits reports whether s is a non-empty run of ASCII digits.
+func isDigits(s string) bool {
+	if s == "" {
+		return false
 	}
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+const (
+	idFormatMountPath          = "{mount}/{path}"
+	idFormatAddressMountPath   = "{address}/{mount}/{path}"
+	idFormatNamespaceMountPath = "{namespace}/{mount}/{path}"
+)
 
-	data := make(map[string]string)
-	for k, v := range result.Data.Data {
-		data[k] = fmt.Sprintf("%v", v)
+// namespaceIDPrefix starts the namespace part of an ID, which ends at the
+// next ':' (e.g. 'ns:team-a:app_envs/my-service/test').
+const namespaceIDPrefix = "ns:"
+
+// resourceID renders the ID of model according to its id_format. A resource
+// namespace is always included, so the same path in two namespaces gets two
+// IDs and the ID can be imported back.
+func (r *KvKeysResource) resourceID(model KvKeysResourceModel) string {
+	id := fmt.Sprintf("%s/%s", secretMount(model), secretPath(model))
+	ns := model.Namespace.ValueString()
+	if ns == "" && model.IDFormat.ValueString() == idFormatNamespaceMountPath {
+		ns = strings.Trim(r.client.Namespace, "/")
+	}
+	if ns != "" {
+		id = namespaceIDPrefix + ns + ":" + id
+	}
+	if model.IDFormat.ValueString() == idFormatAddressMountPath {
+		id = r.client.Address + "/" + id
 	}
+	return id
+}
+
+// templateMarkers are the delimiters of Terraform, Go and Jinja-style
+// templates.
+var templateMarkers = []string{"${", "%{", "{{", "}}"}
 
-	return data, nil
+// templateMarker returns the first template delimiter found in s.
+func templateMarker(s string) (string, bool) {
+	for _, marker := range templateMarkers {
+		if strings.Contains(s, marker) {
+			return marker, true
+		}
+	}
+	return "", false
 }
 
-func (r *KvKeysResource) writeSecret(mount, path string, data map[string]string) error {
-	url := fmt.Sprintf("%s/v1/%s/data/%s", r.client.Address, mount, path)
+// secretPath returns the path of model's secret, with trailing slashes
+// dropped when trailing_slash is "strip", cleaned by cleanSecretPath.
+func secretPath(model KvKeysResourceModel) string {
+	path := model.Path.ValueString()
+	if model.TrailingSlash.ValueString() == trailingSlashStrip {
+		path = strings.TrimRight(path, "/")
+	}
+	if clean, err := cleanSecretPath(path); err == nil {
+		path = clean
+	}
+	return path
+}
+
+// secretMount returns the mount of model's secret, cleaned by
+// cleanSecretPath.
+func secretMount(model KvKeysResourceModel) string {
+	mount := model.Mount.ValueString()
+	if clean, err := cleanSecretPath(mount); err == nil {
+		mount = clean
+	}
+	return mount
+}
+
+// addTrailingSlashError reports a path ending in "/", which addresses a KV
+// directory instead of a secret. hint says how to proceed.
+func addTrailingSlashError(diags *diag.Diagnostics, path, hint string) bool {
+	if !strings.HasSuffix(path, "/") {
+		return false
+	}
+	detail := fmt.Sprintf("'path' is %q, which ends in '/' and so names a KV directory (what 'vault kv list' takes), "+
+		"not a secret. Remove the trailing slash.", path)
+	if hint != "" {
+		detail += " " + hint
+	}
+	diags.AddAttributeError(tfpath.Root("path"), "Path Is a Directory", detail)
+	return true
+}
+
+// looksSwapped reports whether mount and path look like a secret path was
+// put in 'mount': a mount nested more than two levels deep, or a nested
+// mount next to a single-segment path.
+func looksSwapped(mount, path string) bool {
+	mountSlashes := strings.Count(strings.Trim(mount, "/"), "/")
+	pathSlashes := strings.Count(strings.Trim(path, "/"), "/")
+	return mountSlashes >= 2 || (mountSlashes >= 1 && pathSlashes == 0)
+}
+
+// allowEmptySecret reports whether model may leave its secret without keys.
+func allowEmptySecret(model KvKeysResourceModel) bool {
+	// Deleting the secret would take the siblings of the flattened object
+	// with it.
+	if model.FlattenUnder.ValueString() != "" {
+		return true
+	}
+	return model.AllowEmpty.IsNull() || model.AllowEmpty.ValueBool()
+}
+
+// deleteEmptySecret deletes mount/path in place of writing an empty secret
+// when allow_empty_secret is false.
+func deleteEmptySecret(ctx context.Context, client *VaultClient, mount, path string) error {
+	client.logOperation(ctx, "No keys would remain and allow_empty_secret is false, deleting the secret instead", map[string]interface{}{
+		"mount": mount,
+		"path":  path,
+	})
+	if err := client.deleteSecret(ctx, mount, path); err != nil {
+		if errors.Is(err, errPermissionDenied) {
+			return fmt.Errorf("no keys would remain and allow_empty_secret is false, but the secret could not be "+
+				"deleted instead: %w. Grant the token 'delete' on the path or set allow_empty_secret = true", err)
+		}
+		return err
+	}
+	return nil
+}
+
+// casRetries returns the merge-retry retry limit and backoff of model: the
+// provider's backoff, starting at cas_backoff_ms when that is set.
+func casRetries(model KvKeysResourceModel, backoff retryBackoff) (int, retryBackoff) {
+	retries := casMaxRetries
+	if !model.CASMaxRetries.IsNull() {
+		retries = int(model.CASMaxRetries.ValueInt64())
+	}
+	if !model.CASBackoffMs.IsNull() {
+		backoff.Base = time.Duration(model.CASBackoffMs.ValueInt64()) * time.Millisecond
+	}
+	return retries, backoff
+}
+
+// backupKeys writes keys to model's backup_file, if any. keys are the
+// configured values, before transforms and value_command, so the backup can
+// be pasted back into 'keys'. A failure is only a warning since the write to
+// Vault itself succeeded.
+func backupKeys(model KvKeysResourceModel, keys map[string]string, version int64, diags *diag.Diagnostics) {
+	file := model.BackupFile.ValueString()
+	if file == "" || !model.AllowPlaintextBackup.ValueBool() {
+		return
+	}
+
+	err := writeBackupFile(file, backupContent{
+		Mount:     secretMount(model),
+		Path:      secretPath(model),
+		Version:   version,
+		WrittenAt: time.Now().UTC(),
+		Keys:      keys,
+	})
+	if err != nil {
+		diags.AddAttributeWarning(
+			tfpath.Root("backup_file"),
+			"Backup File Not Written",
+			fmt.Sprintf("The secret was written to Vault, but the backup to %s failed: %s", file, err),
+		)
+	}
+}
+
+// applyCustomMetadata sets client's custom_metadata on mount/path when no
+// write did. A failure is only a warning since the keys are in place.
+func applyCustomMetadata(ctx context.Context, client *VaultClient, mount, path string, diags *diag.Diagnostics) {
+	if err := client.applyCustomMetadata(ctx, mount, path); err != nil {
+		diags.AddAttributeWarning(
+			tfpath.Root("custom_metadata"),
+			"Custom Metadata Not Applied",
+			fmt.Sprintf("Could not set custom_metadata on %s/%s: %s", mount, path, err),
+		)
+	}
+}
 
-	payload := map[string]interface{}{
-		"data": data,
+// softDeletedVersion returns the latest version of mount/path when it is
+// soft-deleted. A metadata read failure counts as not deleted.
+func softDeletedVersion(ctx context.Context, client *VaultClient, mount, path string) (int64, bool) {
+	metadata, err := client.readMetadata(ctx, mount, path)
+	if err != nil || metadata == nil || !metadata.latestSoftDeleted() {
+		return 0, false
 	}
+	return metadata.CurrentVersion, true
+}
 
-	body, err := json.Marshal(payload)
+// restoreSoftDeleted undeletes the latest version of mount/path when it is
+// soft-deleted, so the following read and merge start from its data.
+func restoreSoftDeleted(ctx context.Context, client *VaultClient, mount, path string) error {
+	metadata, err := client.readMetadata(ctx, mount, path)
 	if err != nil {
-		return fmt.Errorf("failed to marshal payload: %w", err)
+		return err
+	}
+	if !metadata.latestSoftDeleted() {
+		return nil
 	}
 
-	req, err := http.NewRequest("POST", url, bytes.NewBuffer(body))
+	client.logOperation(ctx, "Latest version is soft-deleted, undeleting it before writing", map[string]interface{}{
+		"mount":   mount,
+		"path":    path,
+		"version": metadata.CurrentVersion,
+	})
+	return client.undeleteVersion(ctx, mount, path, metadata.CurrentVersion)
+}
+
+// verifyKeyCount reads back the version of mount/path a write produced and
+// checks it holds as many keys as were written, so a merge bug cannot drop
+// other writers' keys unnoticed. Only key names are reported.
+func verifyKeyCount(ctx context.Context, client *VaultClient, mount, path string, written map[string]string, version int64) error {
+	stored, _, err := client.fetchSecretData(ctx, mount, path, version)
 	if err != nil {
-		return fmt.Errorf("failed to create request: %w", err)
+		return fmt.Errorf("could not read back %s/%s to verify its keys: %w", mount, path, err)
+	}
+	if len(stored) == len(written) {
+		return nil
 	}
-	req.Header.Set("X-Vault-Token", r.client.Token)
-	req.Header.Set("Content-Type", "application/json")
 
-	resp, err := r.client.HTTPClient.Do(req)
+	var lost []string
+	for key := range written {
+		if _, ok := stored[key]; !ok {
+			lost = append(lost, key)
+		}
+	}
+	sort.Strings(lost)
+	if len(lost) == 0 {
+		return fmt.Errorf("%s/%s holds %d keys after the write, expected %d",
+			mount, path, len(stored), len(written))
+	}
+	return fmt.Errorf("%s/%s holds %d keys after the write, expected %d; missing: %s",
+		mount, path, len(stored), len(written), strings.Join(lost, ", "))
+}
+
+// defaultVersionPressureMargin is the 'version_pressure_margin' default.
+const defaultVersionPressureMargin = 2
+
+// checkVersionPressure warns when model's secret keeps nearly as many
+// versions as max_versions allows, if warn_on_version_pressure is set.
+// Failing to read the limits is only logged.
+func checkVersionPressure(ctx context.Context, client *VaultClient, model KvKeysResourceModel, diags *diag.Diagnostics) {
+	if !model.WarnOnVersionPressure.ValueBool() || client.kvV1() {
+		return
+	}
+	mount := secretMount(model)
+	path := secretPath(model)
+
+	metadata, err := client.readMetadata(ctx, mount, path)
+	maxVersions := int64(0)
+	if err == nil {
+		maxVersions = metadata.MaxVersions
+		if maxVersions == 0 {
+			maxVersions, err = client.mountMaxVersions(ctx, mount)
+		}
+	}
 	if err != nil {
-		return fmt.Errorf("failed to send request: %w", err)
+		tflog.Warn(ctx, "Could not check version pressure", map[string]interface{}{
+			"mount": mount,
+			"path":  path,
+			"error": err.Error(),
+		})
+		return
+	}
+
+	margin := int64(defaultVersionPressureMargin)
+	if !model.VersionPressureMargin.IsNull() {
+		margin = model.VersionPressureMargin.ValueInt64()
+	}
+	kept := metadata.CurrentVersion - metadata.OldestVersion + 1
+	if metadata.OldestVersion == 0 {
+		kept = metadata.CurrentVersion
+	}
+	if kept < maxVersions-margin {
+		return
 	}
-	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusNoContent {
-		respBody, _ := io.ReadAll(resp.Body)
-		return fmt.Errorf("vault returned status %d: %s", resp.StatusCode, string(respBody))
+	diags.AddAttributeWarning(
+		tfpath.Root("warn_on_version_pressure"),
+		"Version History Near max_versions",
+		fmt.Sprintf("%s/%s keeps %d versions and max_versions is %d. Once the limit is reached every write drops "+
+			"the oldest version; raise max_versions or reduce how often the secret changes to keep more history.",
+			mount, path, kept, maxVersions),
+	)
+}
+
+// writeErrorSummary picks the diagnostic summary for a failed write.
+func writeErrorSummary(err error) string {
+	if errors.Is(err, errPayloadTooLarge) {
+		return "Secret Too Large"
 	}
+	return "Failed to Write Secret"
+}
 
-	return nil
+func addReadOnlyError(diags *diag.Diagnostics) {
+	diags.AddError(
+		"Provider Is in Read-Only Mode",
+		"read_only is enabled on the provider, so resources cannot be created, updated, or deleted. "+
+			"Data sources and refresh still work. Disable read_only to make changes.",
+	)
+}
+
+func addDryRunWarning(diags *diag.Diagnostics, mount, path string) {
+	diags.AddWarning(
+		"Dry Run: Secret Not Written",
+		fmt.Sprintf("dry_run is enabled on the provider, so nothing was written to %s/%s. "+
+			"Terraform state records the intended result; disable dry_run and re-apply to make the change.", mount, path),
+	)
+}
+
+const (
+	jsonFormatCompact = "compact"
+	jsonFormatIndent  = "indent"
+)
+
+// jsonEncodingFor returns the encoding configured for the structured keys
+// of model.
+func jsonEncodingFor(model KvKeysResourceModel) jsonEncoding {
+	enc := defaultJSONEncoding
+	if model.JSONFormat.ValueString() == jsonFormatIndent {
+		enc.Indent = "  "
+	}
+	if !model.JSONEscapeHTML.IsNull() {
+		enc.EscapeHTML = model.JSONEscapeHTML.ValueBool()
+	}
+	return enc
+}
+
+// jsonPointerFields decodes a json_pointers map; null decodes as empty.
+func jsonPointerFields(ctx context.Context, pointers types.Map) (map[string]map[string]string, diag.Diagnostics) {
+	fields := make(map[string]map[string]string)
+	if pointers.IsNull() || pointers.IsUnknown() {
+		return fields, nil
+	}
+	diags := pointers.ElementsAs(ctx, &fields, false)
+	return fields, diags
+}
+
+// checkJSONSchemas validates the value of every key in keys that has a
+// schema in schemas, reporting each violation against the key.
+func checkJSONSchemas(ctx context.Context, schemas types.Map, keys map[string]string) diag.Diagnostics {
+	var diags diag.Diagnostics
+	if schemas.IsNull() || schemas.IsUnknown() {
+		return diags
+	}
+
+	docs := make(map[string]string)
+	diags.Append(schemas.ElementsAs(ctx, &docs, false)...)
+	if diags.HasError() {
+		return diags
+	}
+
+	for _, key := range sortedKeys(docs) {
+		value, ok := keys[key]
+		if !ok {
+			continue
+		}
+		schema, err := parseJSONSchema(docs[key])
+		if err != nil {
+			diags.AddAttributeError(tfpath.Root("json_schema").AtMapKey(key), "Invalid JSON Schema", err.Error())
+			continue
+		}
+
+		var doc interface{}
+		if err := json.Unmarshal([]byte(value), &doc); err != nil {
+			diags.AddAttributeError(
+				tfpath.Root("keys").AtMapKey(key),
+				"Value Is Not JSON",
+				fmt.Sprintf("Key %q has a JSON schema but its value is not valid JSON: %s", key, err),
+			)
+			continue
+		}
+		if violations := schema.validate(doc); len(violations) > 0 {
+			diags.AddAttributeError(
+				tfpath.Root("keys").AtMapKey(key),
+				"Value Does Not Match JSON Schema",
+				fmt.Sprintf("Key %q violates its schema:\n  %s", key, strings.Join(violations, "\n  ")),
+			)
+		}
+	}
+	return diags
+}
+
+// pointerNames lists the pointers of each key in fields.
+func pointerNames(fields map[string]map[string]string) map[string][]string {
+	names := make(map[string][]string, len(fields))
+	for key, pointers := range fields {
+		for pointer := range pointers {
+			names[key] = append(names[key], pointer)
+		}
+	}
+	return names
+}
+
+// currentJSONPointers looks up the managed pointer fields in the live data,
+// dropping any that no longer resolve.
+func currentJSONPointers(existing map[string]string, fields map[string]map[string]string) map[string]map[string]string {
+	current := make(map[string]map[string]string)
+	for key, pointers := range fields {
+		raw, exists := existing[key]
+		if !exists {
+			continue
+		}
+		doc, err := decodeStructuredValue(raw, true)
+		if err != nil {
+			continue
+		}
+		for pointer := range pointers {
+			tokens, err := parseJSONPointer(pointer)
+			if err != nil {
+				continue
+			}
+			if value, ok := getJSONPointer(doc, tokens); ok {
+				if current[key] == nil {
+					current[key] = make(map[string]string)
+				}
+				current[key][pointer] = jsonPointerValue(value)
+			}
+		}
+	}
+	return current
+}
+
+// selectWorkspaceKeys returns the workspace_keys entry for workspace, or the
+// "default" entry when there is none.
+func selectWorkspaceKeys(workspaceKeys types.Map, workspace string) (types.Map, bool) {
+	entries := workspaceKeys.Elements()
+	entry, ok := entries[workspace]
+	if !ok {
+		entry, ok = entries["default"]
+	}
+	if !ok {
+		return types.Map{}, false
+	}
+	keys, ok := entry.(types.Map)
+	return keys, ok
+}
+
+func sortedMapKeys(m types.Map) []string {
+	names := make([]string, 0, len(m.Elements()))
+	for name := range m.Elements() {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// driftedKeys returns, sorted, the managed keys whose live value differs from
+// state: set keys that are missing or changed, and null keys that exist.
+// Keys in valueTypes are compared as values of their type.
+func driftedKeys(existing, stateKeys map[string]string, nullKeys []string, valueTypes map[string]string) []string {
+	var drifted []string
+	for key, want := range stateKeys {
+		if got, ok := existing[key]; !ok || !sameTypedValue(valueTypes[key], want, got) {
+			drifted = append(drifted, key)
+		}
+	}
+	for _, key := range nullKeys {
+		if _, ok := existing[key]; ok {
+			drifted = append(drifted, key)
+		}
+	}
+	sort.Strings(drifted)
+	return drifted
+}
+
+// keysChecksum returns the hex SHA-256 of the key/value pairs of keys in key
+// order, so the result does not depend on map iteration order. Keys and
+// values are length-prefixed, so no separator inside them (e.g. '=' in
+// {"a": "b=c"} and {"a=b": "c"}) can make two maps hash alike.
+func keysChecksum(keys map[string]string) string {
+	h := sha256.New()
+	for _, key := range sortedKeys(keys) {
+		fmt.Fprintf(h, "%d:%s%d:%s", len(key), key, len(keys[key]), keys[key])
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// mapKnown reports whether m and all of its elements are known.
+func mapKnown(m types.Map) bool {
+	if m.IsUnknown() {
+		return false
+	}
+	for _, elem := range m.Elements() {
+		if elem.IsUnknown() {
+			return false
+		}
+	}
+	return true
+}
+
+// defaultLargeValueBytes is the 'large_value_bytes' default.
+const defaultLargeValueBytes = 1024
+
+// largeValueSummaries returns the 'large_value_summaries' value for keys: a
+// short hash and the length of each value of at least the configured size,
+// or null when summarize_large_values is not set.
+func largeValueSummaries(model KvKeysResourceModel, keys map[string]string) types.Map {
+	if !model.SummarizeLargeValues.ValueBool() {
+		return types.MapNull(types.StringType)
+	}
+	threshold := int64(defaultLargeValueBytes)
+	if !model.LargeValueBytes.IsNull() {
+		threshold = model.LargeValueBytes.ValueInt64()
+	}
+	summaries := make(map[string]attr.Value)
+	for key, value := range keys {
+		if int64(len(value)) < threshold {
+			continue
+		}
+		sum := sha256.Sum256([]byte(value))
+		summaries[key] = types.StringValue(fmt.Sprintf("sha256:%s, %d bytes", hex.EncodeToString(sum[:6]), len(value)))
+	}
+	return types.MapValueMust(types.StringType, summaries)
 }
 
 func mergeKeys(existingData, newKeys map[string]string) map[string]string {
@@ -437,6 +2214,29 @@ func mergeKeys(existingData, newKeys map[string]string) map[string]string {
 	return merged
 }
 
+// splitKeys separates the set elements of a keys map from the null ones.
+// Null key names are returned sorted.
+func splitKeys(keys types.Map) (map[string]string, []string) {
+	values := make(map[string]string)
+	var nulls []string
+	for key, elem := range keys.Elements() {
+		str, ok := elem.(types.String)
+		if !ok || str.IsNull() {
+			nulls = append(nulls, key)
+			continue
+		}
+		values[key] = str.ValueString()
+	}
+	sort.Strings(nulls)
+	return values, nulls
+}
+
+func nullKeysDetail(nullKeys []string) string {
+	return fmt.Sprintf("The following keys have a null value: %s. "+
+		"Set a value, remove the keys, or set 'null_means_delete = true' to delete them from the secret.",
+		strings.Join(nullKeys, ", "))
+}
+
 func keysMatch(existing, planned map[string]string) bool {
 	for k, v := range planned {
 		if ev, ok := existing[k]; !ok || ev != v {
@@ -447,10 +2247,15 @@ func keysMatch(existing, planned map[string]string) bool {
 }
 
 func keysOnly(m map[string]string) string {
+	return strings.Join(sortedKeys(m), ", ")
+}
+
+// sortedKeys returns the keys of m in sorted order.
+func sortedKeys(m map[string]string) []string {
 	keys := make([]string, 0, len(m))
 	for k := range m {
 		keys = append(keys, k)
 	}
 	sort.Strings(keys)
-	return strings.Join(keys, ", ")
+	return keys
 }
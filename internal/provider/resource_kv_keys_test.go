@@ -0,0 +1,573 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+)
+
+// importKvKeys imports id into a vaultpatch_kv_keys resource backed by
+// client and returns the resulting state.
+func importKvKeys(t *testing.T, client *VaultClient, id string) (KvKeysResourceModel, *resource.ImportStateResponse) {
+	t.Helper()
+	ctx := context.Background()
+	r := &KvKeysResource{client: client}
+
+	var schemaResp resource.SchemaResponse
+	r.Schema(ctx, resource.SchemaRequest{}, &schemaResp)
+	resp := &resource.ImportStateResponse{
+		State: tfsdk.State{
+			Schema: schemaResp.Schema,
+			Raw:    tftypes.NewValue(schemaResp.Schema.Type().TerraformType(ctx), nil),
+		},
+	}
+	r.ImportState(ctx, resource.ImportStateRequest{ID: id}, resp)
+
+	var state KvKeysResourceModel
+	if !resp.Diagnostics.HasError() {
+		resp.Diagnostics.Append(resp.State.Get(ctx, &state)...)
+	}
+	return state, resp
+}
+
+// writeJSON writes v as the JSON response body.
+func writeJSON(t *testing.T, w http.ResponseWriter, v interface{}) {
+	t.Helper()
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		t.Error(err)
+	}
+}
+
+// kvV2Handler serves the KV v2 secret data at mount/path and its mount.
+func kvV2Handler(t *testing.T, mount, path string, data map[string]interface{}, version int64) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v1/sys/mounts/" + mount:
+			writeJSON(t, w, map[string]interface{}{
+				"data": map[string]interface{}{
+					"type":     "kv",
+					"accessor": "kv_test",
+					"options":  map[string]string{"version": "2"},
+				},
+			})
+		case "/v1/" + mount + "/data/" + path:
+			writeJSON(t, w, map[string]interface{}{
+				"data": map[string]interface{}{
+					"data":     data,
+					"metadata": map[string]interface{}{"version": version},
+				},
+			})
+		default:
+			http.NotFound(w, r)
+		}
+	}
+}
+
+func TestImportState(t *testing.T) {
+	tests := []struct {
+		name, path, id string
+		wantPinned     int64
+	}{
+		{"plain", "my-service/test", "app/my-service/test", 3},
+		{"pinned", "my-service/test", "app/my-service/test@2", 2},
+		{"at sign in path", "users/alice@example.com", "app/users/alice@example.com", 3},
+		{"at sign in path, pinned", "users/alice@example.com", "app/users/alice@example.com@2", 2},
+		{"trailing at sign", "users/alice@", "app/users/alice@", 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := newTestClient(t, kvV2Handler(t, "app", tt.path, map[string]interface{}{"API_KEY": "abc"}, 3))
+
+			state, resp := importKvKeys(t, client, tt.id)
+			if resp.Diagnostics.HasError() {
+				t.Fatalf("unexpected errors: %v", resp.Diagnostics)
+			}
+			if got := state.Path.ValueString(); got != tt.path {
+				t.Errorf("path = %q, want %q", got, tt.path)
+			}
+			if got, want := state.ID.ValueString(), "app/"+tt.path; got != want {
+				t.Errorf("ID = %q, want %q", got, want)
+			}
+			keys, _ := splitKeys(state.Keys)
+			if len(keys) != 1 || keys["API_KEY"] != "abc" {
+				t.Errorf("keys = %v", keys)
+			}
+			if state.PinnedVersion.ValueInt64() != tt.wantPinned {
+				t.Errorf("pinned_version = %d, want %d", state.PinnedVersion.ValueInt64(), tt.wantPinned)
+			}
+		})
+	}
+
+	client := newTestClient(t, kvV2Handler(t, "app", "svc", nil, 3))
+	if _, resp := importKvKeys(t, client, "app/svc@0"); !resp.Diagnostics.HasError() {
+		t.Error("expected an error for version 0")
+	}
+}
+
+func TestImportStateCleansID(t *testing.T) {
+	client := newTestClient(t, kvV2Handler(t, "app", "my-service/test", map[string]interface{}{"API_KEY": "abc"}, 3))
+
+	state, resp := importKvKeys(t, client, "app//my-service/./test/")
+	if resp.Diagnostics.HasError() {
+		t.Fatalf("unexpected errors: %v", resp.Diagnostics)
+	}
+	if got := state.ID.ValueString(); got != "app/my-service/test" {
+		t.Errorf("ID = %q, want %q", got, "app/my-service/test")
+	}
+	if state.Mount.ValueString() != "app" || state.Path.ValueString() != "my-service/test" {
+		t.Errorf("mount/path = %q/%q", state.Mount.ValueString(), state.Path.ValueString())
+	}
+	if state.PinnedVersion.ValueInt64() != 3 {
+		t.Errorf("pinned_version = %d, want 3", state.PinnedVersion.ValueInt64())
+	}
+}
+
+func TestImportStateRejectsEscapingID(t *testing.T) {
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		t.Errorf("unexpected request to %s", r.URL.Path)
+	})
+
+	if _, resp := importKvKeys(t, client, "app/../../sys/mounts"); !resp.Diagnostics.HasError() {
+		t.Error("expected an error for an ID climbing above its root")
+	}
+}
+
+// kvV1Handler serves the KV v1 secret data at mount/path and its mount.
+func kvV1Handler(t *testing.T, mount, path string, data map[string]interface{}) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v1/sys/mounts/" + mount:
+			writeJSON(t, w, map[string]interface{}{
+				"data": map[string]interface{}{
+					"type":     "kv",
+					"accessor": "kv_test",
+					"options":  map[string]string{"version": "1"},
+				},
+			})
+		case "/v1/" + mount + "/" + path:
+			writeJSON(t, w, map[string]interface{}{"data": data})
+		default:
+			http.NotFound(w, r)
+		}
+	}
+}
+
+func TestImportStateDetectsKVv1(t *testing.T) {
+	client := newTestClient(t, kvV1Handler(t, "legacy", "my-service/test", map[string]interface{}{"API_KEY": "abc"}))
+
+	state, resp := importKvKeys(t, client, "legacy/my-service/test")
+	if resp.Diagnostics.HasError() {
+		t.Fatalf("unexpected errors: %v", resp.Diagnostics)
+	}
+	if state.KVVersion.ValueInt64() != 1 {
+		t.Errorf("kv_version = %d, want 1", state.KVVersion.ValueInt64())
+	}
+	keys, _ := splitKeys(state.Keys)
+	if len(keys) != 1 || keys["API_KEY"] != "abc" {
+		t.Errorf("keys = %v", keys)
+	}
+	if state.MountAccessor.ValueString() != "kv_test" {
+		t.Errorf("mount_accessor = %q, want %q", state.MountAccessor.ValueString(), "kv_test")
+	}
+
+	if _, resp := importKvKeys(t, client, "legacy/my-service/test@2"); !resp.Diagnostics.HasError() {
+		t.Error("expected an error pinning a version on a KV v1 mount")
+	}
+}
+
+func TestImportStateAssumesKVv2WithoutMountAccess(t *testing.T) {
+	handler := kvV2Handler(t, "app", "my-service/test", map[string]interface{}{"API_KEY": "abc"}, 3)
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/v1/sys/mounts/app" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		handler(w, r)
+	})
+
+	state, resp := importKvKeys(t, client, "app/my-service/test")
+	if resp.Diagnostics.HasError() {
+		t.Fatalf("unexpected errors: %v", resp.Diagnostics)
+	}
+	if resp.Diagnostics.WarningsCount() != 1 {
+		t.Errorf("expected one warning, got %v", resp.Diagnostics)
+	}
+	if state.KVVersion.ValueInt64() != 2 {
+		t.Errorf("kv_version = %d, want 2", state.KVVersion.ValueInt64())
+	}
+	if keys, _ := splitKeys(state.Keys); keys["API_KEY"] != "abc" {
+		t.Errorf("keys = %v", keys)
+	}
+}
+
+func TestImportStateNamespace(t *testing.T) {
+	handler := kvV2Handler(t, "app", "my-service/test", map[string]interface{}{"API_KEY": "abc"}, 3)
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if got := r.Header.Get("X-Vault-Namespace"); got != "team-a/sub" {
+			t.Errorf("%s: X-Vault-Namespace = %q, want %q", r.URL.Path, got, "team-a/sub")
+		}
+		handler(w, r)
+	})
+
+	state, resp := importKvKeys(t, client, "ns:team-a/sub/:app/my-service/test@3")
+	if resp.Diagnostics.HasError() {
+		t.Fatalf("unexpected errors: %v", resp.Diagnostics)
+	}
+	if got := state.Namespace.ValueString(); got != "team-a/sub" {
+		t.Errorf("namespace = %q, want %q", got, "team-a/sub")
+	}
+	if got, want := state.ID.ValueString(), "ns:team-a/sub:app/my-service/test"; got != want {
+		t.Errorf("ID = %q, want %q", got, want)
+	}
+	if keys, _ := splitKeys(state.Keys); keys["API_KEY"] != "abc" {
+		t.Errorf("keys = %v", keys)
+	}
+
+	// The rendered ID imports back to the same resource.
+	again, resp := importKvKeys(t, client, state.ID.ValueString())
+	if resp.Diagnostics.HasError() {
+		t.Fatalf("unexpected errors re-importing %s: %v", state.ID.ValueString(), resp.Diagnostics)
+	}
+	if again.ID != state.ID || again.Namespace != state.Namespace {
+		t.Errorf("re-import = %s in %s, want %s in %s", again.ID, again.Namespace, state.ID, state.Namespace)
+	}
+
+	for _, id := range []string{"ns::app/my-service/test", "ns:team-a/app/my-service/test"} {
+		if _, resp := importKvKeys(t, client, id); !resp.Diagnostics.HasError() {
+			t.Errorf("expected an error for %q", id)
+		}
+	}
+}
+
+func TestImportStateIDFormatRoundTrip(t *testing.T) {
+	handler := kvV2Handler(t, "app", "db:primary", map[string]interface{}{"API_KEY": "abc"}, 3)
+	client := newTestClient(t, http.StripPrefix("/vault", handler).ServeHTTP)
+	client.Address += "/vault"
+	client.Namespace = "team-a"
+	r := &KvKeysResource{client: client}
+
+	tests := []struct {
+		format, namespace string
+		wantID            string
+	}{
+		{idFormatMountPath, "", "app/db:primary"},
+		{idFormatMountPath, "team-b", "ns:team-b:app/db:primary"},
+		{idFormatAddressMountPath, "", client.Address + "/app/db:primary"},
+		{idFormatAddressMountPath, "team-b", client.Address + "/ns:team-b:app/db:primary"},
+		{idFormatNamespaceMountPath, "", "ns:team-a:app/db:primary"},
+		{idFormatNamespaceMountPath, "team-b", "ns:team-b:app/db:primary"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.format+" "+tt.namespace, func(t *testing.T) {
+			model := KvKeysResourceModel{
+				Mount:    types.StringValue("app"),
+				Path:     types.StringValue("db:primary"),
+				IDFormat: types.StringValue(tt.format),
+			}
+			if tt.namespace != "" {
+				model.Namespace = types.StringValue(tt.namespace)
+			}
+			id := r.resourceID(model)
+			if id != tt.wantID {
+				t.Errorf("resourceID = %q, want %q", id, tt.wantID)
+			}
+
+			state, resp := importKvKeys(t, client, id)
+			if resp.Diagnostics.HasError() {
+				t.Fatalf("unexpected errors importing %s: %v", id, resp.Diagnostics)
+			}
+			if got := state.ID.ValueString(); got != id {
+				t.Errorf("imported ID = %q, want %q", got, id)
+			}
+			if got := state.Namespace.ValueString(); got != tt.namespace {
+				t.Errorf("imported namespace = %q, want %q", got, tt.namespace)
+			}
+			if got := state.Path.ValueString(); got != "db:primary" {
+				t.Errorf("imported path = %q, want %q", got, "db:primary")
+			}
+		})
+	}
+
+	for _, id := range []string{"https://other.example.com/app/db:primary", "http://vault.example.com:8200/app/db:primary"} {
+		if _, resp := importKvKeys(t, client, id); !resp.Diagnostics.HasError() {
+			t.Errorf("expected an error importing %q", id)
+		}
+	}
+}
+
+func TestImportStateColonInPath(t *testing.T) {
+	handler := kvV2Handler(t, "app", "db:primary", map[string]interface{}{"API_KEY": "abc"}, 3)
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if got := r.Header.Get("X-Vault-Namespace"); got != "" {
+			t.Errorf("%s: X-Vault-Namespace = %q, want none", r.URL.Path, got)
+		}
+		handler(w, r)
+	})
+
+	state, resp := importKvKeys(t, client, "app/db:primary")
+	if resp.Diagnostics.HasError() {
+		t.Fatalf("unexpected errors: %v", resp.Diagnostics)
+	}
+	if !state.Namespace.IsNull() {
+		t.Errorf("namespace = %s, want null", state.Namespace)
+	}
+	if got := state.Path.ValueString(); got != "db:primary" {
+		t.Errorf("path = %q, want %q", got, "db:primary")
+	}
+	if got := state.ID.ValueString(); got != "app/db:primary" {
+		t.Errorf("ID = %q, want %q", got, "app/db:primary")
+	}
+}
+
+func TestImportStateNoContent(t *testing.T) {
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/v1/app/data/my-service/test" {
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		kvV2Handler(t, "app", "", nil, 0)(w, r)
+	})
+
+	state, resp := importKvKeys(t, client, "app/my-service/test")
+	if resp.Diagnostics.HasError() {
+		t.Fatalf("expected a 204 to import as an empty secret, got %v", resp.Diagnostics)
+	}
+	if keys, _ := splitKeys(state.Keys); len(keys) != 0 {
+		t.Errorf("keys = %v, want none", keys)
+	}
+}
+
+// readKvKeys refreshes state through a vaultpatch_kv_keys resource backed by
+// client. The returned model is zero when Read removed the resource.
+func readKvKeys(t *testing.T, client *VaultClient, state KvKeysResourceModel) (KvKeysResourceModel, *resource.ReadResponse) {
+	t.Helper()
+	ctx := context.Background()
+	r := &KvKeysResource{client: client}
+
+	var schemaResp resource.SchemaResponse
+	r.Schema(ctx, resource.SchemaRequest{}, &schemaResp)
+	current := tfsdk.State{Schema: schemaResp.Schema}
+	if diags := current.Set(ctx, &state); diags.HasError() {
+		t.Fatalf("could not build state: %v", diags)
+	}
+	resp := &resource.ReadResponse{State: current}
+	r.Read(ctx, resource.ReadRequest{State: current}, resp)
+
+	var refreshed KvKeysResourceModel
+	if !resp.Diagnostics.HasError() && !resp.State.Raw.IsNull() {
+		resp.Diagnostics.Append(resp.State.Get(ctx, &refreshed)...)
+	}
+	return refreshed, resp
+}
+
+// createKvKeys creates plan through a vaultpatch_kv_keys resource backed by
+// client.
+func createKvKeys(t *testing.T, client *VaultClient, plan KvKeysResourceModel) (KvKeysResourceModel, *resource.CreateResponse) {
+	t.Helper()
+	ctx := context.Background()
+	r := &KvKeysResource{client: client}
+
+	var schemaResp resource.SchemaResponse
+	r.Schema(ctx, resource.SchemaRequest{}, &schemaResp)
+	planned := tfsdk.Plan{Schema: schemaResp.Schema}
+	if diags := planned.Set(ctx, &plan); diags.HasError() {
+		t.Fatalf("could not build plan: %v", diags)
+	}
+	resp := &resource.CreateResponse{State: tfsdk.State{
+		Schema: schemaResp.Schema,
+		Raw:    tftypes.NewValue(schemaResp.Schema.Type().TerraformType(ctx), nil),
+	}}
+	r.Create(ctx, resource.CreateRequest{Plan: planned}, resp)
+
+	var created KvKeysResourceModel
+	if !resp.Diagnostics.HasError() {
+		resp.Diagnostics.Append(resp.State.Get(ctx, &created)...)
+	}
+	return created, resp
+}
+
+func TestCreateNullKeys(t *testing.T) {
+	tests := map[string]struct {
+		nullMeansDelete bool
+		want            map[string]interface{}
+	}{
+		"rejected": {false, map[string]interface{}{"API_KEY": "abc", "OLD": "x"}},
+		"deleted":  {true, map[string]interface{}{"API_KEY": "new"}},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			store := newKVStore(t, "app")
+			store.versions["svc"] = []map[string]interface{}{{"API_KEY": "abc", "OLD": "x"}}
+			client := newTestClient(t, store.ServeHTTP)
+
+			plan, resp := importKvKeys(t, client, "app/svc")
+			if resp.Diagnostics.HasError() {
+				t.Fatal(resp.Diagnostics)
+			}
+			plan.Keys = types.MapValueMust(types.StringType, map[string]attr.Value{
+				"API_KEY": types.StringValue("new"),
+				"OLD":     types.StringNull(),
+				"GONE":    types.StringNull(),
+			})
+			plan.NullMeansDelete = types.BoolValue(tt.nullMeansDelete)
+
+			_, createResp := createKvKeys(t, client, plan)
+			if got := createResp.Diagnostics.HasError(); got == tt.nullMeansDelete {
+				t.Fatalf("error = %v, want %v: %v", got, !tt.nullMeansDelete, createResp.Diagnostics)
+			}
+			if !tt.nullMeansDelete {
+				errs := createResp.Diagnostics.Errors()
+				if len(errs) != 1 || errs[0].Summary() != "Null Value in Keys" ||
+					!strings.Contains(errs[0].Detail(), "GONE, OLD") || !strings.Contains(errs[0].Detail(), "null_means_delete") {
+					t.Errorf("unexpected diagnostics: %v", createResp.Diagnostics)
+				}
+			}
+			if got := store.latest("svc"); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("stored %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestReadPinWarning(t *testing.T) {
+	tests := map[string]struct {
+		live        map[string]interface{}
+		wantWarning bool
+	}{
+		"other keys changed":  {map[string]interface{}{"API_KEY": "abc", "OTHER": "new"}, false},
+		"managed key changed": {map[string]interface{}{"API_KEY": "rotated"}, true},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			data := map[string]interface{}{"API_KEY": "abc"}
+			version := int64(3)
+			client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+				kvV2Handler(t, "app", "svc", data, version)(w, r)
+			})
+			state, resp := importKvKeys(t, client, "app/svc")
+			if resp.Diagnostics.HasError() {
+				t.Fatal(resp.Diagnostics)
+			}
+
+			data, version = tt.live, 4
+			refreshed, readResp := readKvKeys(t, client, state)
+			if readResp.Diagnostics.HasError() {
+				t.Fatal(readResp.Diagnostics)
+			}
+			if got := readResp.Diagnostics.WarningsCount() > 0; got != tt.wantWarning {
+				t.Errorf("warning = %v, want %v: %v", got, tt.wantWarning, readResp.Diagnostics)
+			}
+			if refreshed.CurrentVersion.ValueInt64() != 4 {
+				t.Errorf("current_version = %d, want 4", refreshed.CurrentVersion.ValueInt64())
+			}
+		})
+	}
+}
+
+func TestReadSoftDeletedLatestVersion(t *testing.T) {
+	tests := map[string]struct {
+		deletionTime  string
+		undeleteFirst bool
+		wantError     bool
+		wantWarning   bool
+		wantRemoved   bool
+	}{
+		"reported":             {"2024-01-02T03:04:05.123456Z", false, true, false, false},
+		"undeleted on apply":   {"2024-01-02T03:04:05.123456Z", true, false, true, false},
+		"deletion not yet due": {"2999-01-01T00:00:00Z", false, false, false, true},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			deleted := false
+			live := kvV2Handler(t, "app", "svc", map[string]interface{}{"API_KEY": "abc"}, 2)
+			client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+				if !deleted {
+					live(w, r)
+					return
+				}
+				switch r.URL.Path {
+				case "/v1/app/data/svc":
+					// Vault answers 404 for a deleted version.
+					w.WriteHeader(http.StatusNotFound)
+					writeJSON(t, w, map[string]interface{}{
+						"data": map[string]interface{}{
+							"data":     nil,
+							"metadata": map[string]interface{}{"version": 2, "deletion_time": tt.deletionTime},
+						},
+					})
+				case "/v1/app/metadata/svc":
+					writeJSON(t, w, map[string]interface{}{
+						"data": map[string]interface{}{
+							"current_version": 2,
+							"versions": map[string]interface{}{
+								"1": map[string]interface{}{"deletion_time": "", "destroyed": false},
+								"2": map[string]interface{}{"deletion_time": tt.deletionTime, "destroyed": false},
+							},
+						},
+					})
+				default:
+					http.NotFound(w, r)
+				}
+			})
+
+			state, resp := importKvKeys(t, client, "app/svc")
+			if resp.Diagnostics.HasError() {
+				t.Fatal(resp.Diagnostics)
+			}
+			state.UndeleteFirst = types.BoolValue(tt.undeleteFirst)
+
+			deleted = true
+			_, readResp := readKvKeys(t, client, state)
+			if got := readResp.Diagnostics.HasError(); got != tt.wantError {
+				t.Fatalf("error = %v, want %v: %v", got, tt.wantError, readResp.Diagnostics)
+			}
+			if got := readResp.Diagnostics.WarningsCount() > 0; got != tt.wantWarning {
+				t.Errorf("warning = %v, want %v: %v", got, tt.wantWarning, readResp.Diagnostics)
+			}
+			for _, d := range readResp.Diagnostics {
+				if d.Summary() != "Latest Secret Version Soft-Deleted" || !strings.Contains(d.Detail(), "Version 2 of app/svc") {
+					t.Errorf("unexpected diagnostic %s: %s", d.Summary(), d.Detail())
+				}
+			}
+			if got := readResp.State.Raw.IsNull(); got != tt.wantRemoved {
+				t.Errorf("removed from state = %v, want %v", got, tt.wantRemoved)
+			}
+		})
+	}
+}
+
+func TestKeysChecksum(t *testing.T) {
+	base := keysChecksum(map[string]string{"API_KEY": "abc", "DB_HOST": "db", "PORT": "5432"})
+
+	// Go randomizes map iteration, so building the map several times also
+	// covers insertion order.
+	for i := 0; i < 10; i++ {
+		if got := keysChecksum(map[string]string{"PORT": "5432", "DB_HOST": "db", "API_KEY": "abc"}); got != base {
+			t.Fatalf("checksum changed with key order: %s, want %s", got, base)
+		}
+	}
+	if got := keysChecksum(map[string]string{"API_KEY": "abd", "DB_HOST": "db", "PORT": "5432"}); got == base {
+		t.Error("checksum did not change when a value was edited")
+	}
+
+	pairs := [][2]map[string]string{
+		{{"a": "b=c"}, {"a=b": "c"}},
+		{{"a": "b\x00c=d"}, {"a": "b", "c": "d"}},
+		{{"a": ""}, {}},
+	}
+	for _, pair := range pairs {
+		if keysChecksum(pair[0]) == keysChecksum(pair[1]) {
+			t.Errorf("%q and %q have the same checksum", pair[0], pair[1])
+		}
+	}
+}
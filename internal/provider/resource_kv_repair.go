@@ -0,0 +1,214 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+var _ resource.Resource = &KvRepairResource{}
+
+type KvRepairResource struct {
+	client *VaultClient
+}
+
+type KvRepairResourceModel struct {
+	ID                types.String `tfsdk:"id"`
+	Mount             types.String `tfsdk:"mount"`
+	Path              types.String `tfsdk:"path"`
+	Keys              types.Map    `tfsdk:"keys"`
+	Triggers          types.Map    `tfsdk:"triggers"`
+	RepairedKeys      types.List   `tfsdk:"repaired_keys"`
+	PathNormalization types.String `tfsdk:"path_normalization"`
+}
+
+func NewKvRepairResource() resource.Resource {
+	return &KvRepairResource{}
+}
+
+func (r *KvRepairResource) Metadata(_ context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_kv_repair"
+}
+
+func (r *KvRepairResource) Schema(_ context.Context, _ resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		Description: "Forces the given keys in a Vault KV v2 secret back to their configured values whenever it is applied. " +
+			"Intended for incident response: change 'triggers' to repair drift outside the normal plan/apply diff of " +
+			"vaultpatch_kv_keys. Destroying this resource does not remove any keys from Vault.",
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				Description: "The unique identifier for this resource (mount/path).",
+				Computed:    true,
+			},
+			"mount": schema.StringAttribute{
+				Description: "The mount path of the KV v2 secrets engine (e.g., 'app_demo').",
+				Required:    true,
+			},
+			"path": schema.StringAttribute{
+				Description: "The path within the mount where the secret lives (e.g., 'my-service/test').",
+				Required:    true,
+			},
+			"path_normalization": schema.StringAttribute{
+				Description: "How to treat 'mount' and 'path' values with duplicate slashes or '.' and '..' segments: " +
+					"'normalize' collapses and resolves them, 'error' rejects them. A '..' that climbs above the value " +
+					"itself is always rejected. Defaults to 'normalize'.",
+				Optional: true,
+			},
+			"keys": schema.MapAttribute{
+				Description: "The key-value pairs to restore. Other keys in the secret are preserved.",
+				Required:    true,
+				Sensitive:   true,
+				ElementType: types.StringType,
+			},
+			"triggers": schema.MapAttribute{
+				Description: "Arbitrary values that cause the repair to run again when changed (e.g., a timestamp or ticket ID).",
+				Optional:    true,
+				ElementType: types.StringType,
+			},
+			"repaired_keys": schema.ListAttribute{
+				Description: "Names of the keys that had drifted and were rewritten during the last repair.",
+				Computed:    true,
+				ElementType: types.StringType,
+			},
+		},
+	}
+}
+
+func (r *KvRepairResource) Configure(_ context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+
+	client, ok := req.ProviderData.(*VaultClient)
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Resource Configure Type",
+			"Expected *VaultClient, got something else.",
+		)
+		return
+	}
+
+	r.client = client
+}
+
+func (r *KvRepairResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	if r.client.ReadOnly {
+		addReadOnlyError(&resp.Diagnostics)
+		return
+	}
+
+	var plan KvRepairResourceModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	resp.Diagnostics.Append(r.repair(ctx, &plan)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &plan)...)
+}
+
+func (r *KvRepairResource) Read(_ context.Context, _ resource.ReadRequest, _ *resource.ReadResponse) {
+	// Drift is only repaired on apply; refresh keeps the last repair result.
+}
+
+func (r *KvRepairResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	if r.client.ReadOnly {
+		addReadOnlyError(&resp.Diagnostics)
+		return
+	}
+
+	var plan KvRepairResourceModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	resp.Diagnostics.Append(r.repair(ctx, &plan)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &plan)...)
+}
+
+func (r *KvRepairResource) Delete(_ context.Context, _ resource.DeleteRequest, _ *resource.DeleteResponse) {
+	// Removing the repair action leaves the secret as it is.
+}
+
+// repair rewrites any key in model whose live value differs from the
+// configured one and records which keys were repaired.
+func (r *KvRepairResource) repair(ctx context.Context, model *KvRepairResourceModel) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	mount, path, ok := cleanSecretPaths(model.Mount.ValueString(), model.Path.ValueString(), model.PathNormalization.ValueString(), &diags)
+	if !ok {
+		return diags
+	}
+
+	wantKeys := make(map[string]string)
+	diags.Append(model.Keys.ElementsAs(ctx, &wantKeys, false)...)
+	if diags.HasError() {
+		return diags
+	}
+	ctx = r.client.maskValues(ctx, wantKeys)
+
+	existingData, err := r.client.readSecret(ctx, mount, path)
+	if err != nil {
+		diags.AddError(
+			"Failed to Read Existing Secret",
+			fmt.Sprintf("Could not read %s/%s: %s", mount, path, err),
+		)
+		return diags
+	}
+
+	repaired := make([]string, 0)
+	for key, want := range wantKeys {
+		if got, ok := existingData[key]; !ok || got != want {
+			repaired = append(repaired, key)
+		}
+	}
+	sort.Strings(repaired)
+
+	if len(repaired) > 0 {
+		r.client.logOperation(ctx, "Repairing drifted keys in Vault", map[string]interface{}{
+			"mount": mount,
+			"path":  path,
+			"keys":  repaired,
+		})
+
+		if _, err := r.client.writeSecret(ctx, mount, path, mergeKeys(existingData, wantKeys)); err != nil {
+			diags.AddError(
+				"Failed to Write Secret",
+				fmt.Sprintf("Could not write to %s/%s: %s", mount, path, err),
+			)
+			return diags
+		}
+		if r.client.DryRun {
+			addDryRunWarning(&diags, mount, path)
+		}
+	} else {
+		r.client.logOperation(ctx, "No drift found, skipping write", map[string]interface{}{
+			"mount": mount,
+			"path":  path,
+		})
+	}
+
+	repairedList, listDiags := types.ListValueFrom(ctx, types.StringType, repaired)
+	diags.Append(listDiags...)
+	if diags.HasError() {
+		return diags
+	}
+
+	model.ID = types.StringValue(fmt.Sprintf("%s/%s", mount, path))
+	model.RepairedKeys = repairedList
+	return diags
+}
@@ -0,0 +1,308 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+var _ resource.Resource = &KvRotatingKeyResource{}
+
+type KvRotatingKeyResource struct {
+	client *VaultClient
+}
+
+type KvRotatingKeyResourceModel struct {
+	ID                types.String `tfsdk:"id"`
+	Mount             types.String `tfsdk:"mount"`
+	Path              types.String `tfsdk:"path"`
+	Key               types.String `tfsdk:"key"`
+	PreviousKey       types.String `tfsdk:"previous_key"`
+	Value             types.String `tfsdk:"value"`
+	PreviousValue     types.String `tfsdk:"previous_value"`
+	PathNormalization types.String `tfsdk:"path_normalization"`
+}
+
+func NewKvRotatingKeyResource() resource.Resource {
+	return &KvRotatingKeyResource{}
+}
+
+func (r *KvRotatingKeyResource) Metadata(_ context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_kv_rotating_key"
+}
+
+func (r *KvRotatingKeyResource) Schema(_ context.Context, _ resource.SchemaRequest, resp *resource.SchemaResponse) {
+	replace := []planmodifier.String{stringplanmodifier.RequiresReplace()}
+
+	resp.Schema = schema.Schema{
+		Description: "Manages a single key in a Vault KV v2 secret together with a second key holding its previous value. " +
+			"When 'value' changes, the live value of 'key' is moved to 'previous_key' in the same write, so consumers " +
+			"can accept both during a rotation grace period. Other keys in the secret are preserved.",
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				Description: "The unique identifier for this resource (mount/path/key).",
+				Computed:    true,
+			},
+			"mount": schema.StringAttribute{
+				Description:   "The mount path of the KV v2 secrets engine (e.g., 'app_demo').",
+				Required:      true,
+				PlanModifiers: replace,
+			},
+			"path": schema.StringAttribute{
+				Description:   "The path within the mount where the secret lives (e.g., 'my-service/test').",
+				Required:      true,
+				PlanModifiers: replace,
+			},
+			"path_normalization": schema.StringAttribute{
+				Description: "How to treat 'mount' and 'path' values with duplicate slashes or '.' and '..' segments: " +
+					"'normalize' collapses and resolves them, 'error' rejects them. A '..' that climbs above the value " +
+					"itself is always rejected. Defaults to 'normalize'.",
+				Optional: true,
+			},
+			"key": schema.StringAttribute{
+				Description:   "The key holding the current value (e.g., 'API_KEY').",
+				Required:      true,
+				PlanModifiers: replace,
+			},
+			"previous_key": schema.StringAttribute{
+				Description:   "The key holding the value before the last rotation (e.g., 'API_KEY_PREVIOUS').",
+				Required:      true,
+				PlanModifiers: replace,
+			},
+			"value": schema.StringAttribute{
+				Description: "The current value. Changing it rotates the old value into 'previous_key'.",
+				Required:    true,
+				Sensitive:   true,
+			},
+			"previous_value": schema.StringAttribute{
+				Description: "The value of 'previous_key' in Vault. Null before the first rotation.",
+				Computed:    true,
+				Sensitive:   true,
+			},
+		},
+	}
+}
+
+func (r *KvRotatingKeyResource) Configure(_ context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+
+	client, ok := req.ProviderData.(*VaultClient)
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Resource Configure Type",
+			"Expected *VaultClient, got something else.",
+		)
+		return
+	}
+
+	r.client = client
+}
+
+func (r *KvRotatingKeyResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	if r.client.ReadOnly {
+		addReadOnlyError(&resp.Diagnostics)
+		return
+	}
+
+	var plan KvRotatingKeyResourceModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if plan.Key.ValueString() == plan.PreviousKey.ValueString() {
+		resp.Diagnostics.AddError(
+			"Key and Previous Key Are Identical",
+			"'key' and 'previous_key' must name different keys.",
+		)
+		return
+	}
+
+	resp.Diagnostics.Append(r.rotate(ctx, &plan)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &plan)...)
+}
+
+func (r *KvRotatingKeyResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	var state KvRotatingKeyResourceModel
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	mount, path, ok := cleanSecretPaths(state.Mount.ValueString(), state.Path.ValueString(), state.PathNormalization.ValueString(), &resp.Diagnostics)
+	if !ok {
+		return
+	}
+	ctx = r.client.maskValues(ctx, map[string]string{
+		"value":          state.Value.ValueString(),
+		"previous_value": state.PreviousValue.ValueString(),
+	})
+
+	existingData, err := r.client.readSecret(ctx, mount, path)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Failed to Read Secret",
+			fmt.Sprintf("Could not read %s/%s: %s", mount, path, err),
+		)
+		return
+	}
+
+	current, exists := existingData[state.Key.ValueString()]
+	if !exists {
+		tflog.Warn(ctx, "Rotating key no longer exists in Vault, removing from state", map[string]interface{}{
+			"mount": mount,
+			"path":  path,
+			"key":   state.Key.ValueString(),
+		})
+		resp.State.RemoveResource(ctx)
+		return
+	}
+
+	state.Value = types.StringValue(current)
+	state.PreviousValue = types.StringNull()
+	if previous, ok := existingData[state.PreviousKey.ValueString()]; ok {
+		state.PreviousValue = types.StringValue(previous)
+	}
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &state)...)
+}
+
+func (r *KvRotatingKeyResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	if r.client.ReadOnly {
+		addReadOnlyError(&resp.Diagnostics)
+		return
+	}
+
+	var plan KvRotatingKeyResourceModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	resp.Diagnostics.Append(r.rotate(ctx, &plan)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &plan)...)
+}
+
+func (r *KvRotatingKeyResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	if r.client.ReadOnly {
+		addReadOnlyError(&resp.Diagnostics)
+		return
+	}
+
+	var state KvRotatingKeyResourceModel
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	mount, path, ok := cleanSecretPaths(state.Mount.ValueString(), state.Path.ValueString(), state.PathNormalization.ValueString(), &resp.Diagnostics)
+	if !ok {
+		return
+	}
+
+	existingData, err := r.client.readSecret(ctx, mount, path)
+	if err != nil {
+		tflog.Warn(ctx, "Could not read secret during delete, assuming already cleaned up", map[string]interface{}{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	delete(existingData, state.Key.ValueString())
+	delete(existingData, state.PreviousKey.ValueString())
+
+	if _, err := r.client.writeSecret(ctx, mount, path, existingData); err != nil {
+		resp.Diagnostics.AddError(
+			"Failed to Write Secret After Delete",
+			fmt.Sprintf("Could not update %s/%s after removing keys: %s", mount, path, err),
+		)
+		return
+	}
+	if r.client.DryRun {
+		addDryRunWarning(&resp.Diagnostics, mount, path)
+	}
+}
+
+// rotate writes the planned value to the current key. When the live current
+// value differs from it, that value is moved to the previous key in the
+// same write.
+func (r *KvRotatingKeyResource) rotate(ctx context.Context, model *KvRotatingKeyResourceModel) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	mount, path, ok := cleanSecretPaths(model.Mount.ValueString(), model.Path.ValueString(), model.PathNormalization.ValueString(), &diags)
+	if !ok {
+		return diags
+	}
+	key := model.Key.ValueString()
+	previousKey := model.PreviousKey.ValueString()
+	value := model.Value.ValueString()
+	ctx = r.client.maskValues(ctx, map[string]string{"value": value})
+
+	existingData, err := r.client.readSecret(ctx, mount, path)
+	if err != nil {
+		diags.AddError(
+			"Failed to Read Existing Secret",
+			fmt.Sprintf("Could not read %s/%s: %s", mount, path, err),
+		)
+		return diags
+	}
+
+	updates := map[string]string{key: value}
+	current, exists := existingData[key]
+	if exists && current != value {
+		updates[previousKey] = current
+	}
+
+	model.ID = types.StringValue(fmt.Sprintf("%s/%s/%s", mount, path, key))
+	model.PreviousValue = types.StringNull()
+	if previous, ok := mergeKeys(existingData, updates)[previousKey]; ok {
+		model.PreviousValue = types.StringValue(previous)
+	}
+
+	if exists && current == value {
+		r.client.logOperation(ctx, "Rotating key already holds the configured value, skipping write", map[string]interface{}{
+			"mount": mount,
+			"path":  path,
+			"key":   key,
+		})
+		return diags
+	}
+
+	r.client.logOperation(ctx, "Rotating key in Vault", map[string]interface{}{
+		"mount":        mount,
+		"path":         path,
+		"key":          key,
+		"previous_key": previousKey,
+		"rotated":      exists,
+	})
+
+	if _, err := r.client.writeSecret(ctx, mount, path, mergeKeys(existingData, updates)); err != nil {
+		diags.AddError(
+			"Failed to Write Secret",
+			fmt.Sprintf("Could not write to %s/%s: %s", mount, path, err),
+		)
+		return diags
+	}
+	if r.client.DryRun {
+		addDryRunWarning(&diags, mount, path)
+	}
+
+	return diags
+}
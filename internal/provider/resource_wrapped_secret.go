@@ -0,0 +1,214 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	tfpath "github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/int64default"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/int64planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/mapplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+var (
+	_ resource.Resource                   = &WrappedSecretResource{}
+	_ resource.ResourceWithValidateConfig = &WrappedSecretResource{}
+)
+
+// defaultWrapTTL is the lifetime of a wrapping token, in seconds, when
+// wrap_ttl_seconds is not set.
+const defaultWrapTTL = 300
+
+type WrappedSecretResource struct {
+	client *VaultClient
+}
+
+type WrappedSecretResourceModel struct {
+	ID                types.String `tfsdk:"id"`
+	Mount             types.String `tfsdk:"mount"`
+	Path              types.String `tfsdk:"path"`
+	WrapTTLSeconds    types.Int64  `tfsdk:"wrap_ttl_seconds"`
+	Triggers          types.Map    `tfsdk:"triggers"`
+	WrappingToken     types.String `tfsdk:"wrapping_token"`
+	WrappingAccessor  types.String `tfsdk:"wrapping_accessor"`
+	ExpiresAt         types.String `tfsdk:"expires_at"`
+	PathNormalization types.String `tfsdk:"path_normalization"`
+}
+
+func NewWrappedSecretResource() resource.Resource {
+	return &WrappedSecretResource{}
+}
+
+func (r *WrappedSecretResource) Metadata(_ context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_wrapped_secret"
+}
+
+func (r *WrappedSecretResource) Schema(_ context.Context, _ resource.SchemaRequest, resp *resource.SchemaResponse) {
+	replace := []planmodifier.String{stringplanmodifier.RequiresReplace()}
+
+	resp.Schema = schema.Schema{
+		Description: "Reads a Vault KV secret with response wrapping and exposes the single-use wrapping token, for " +
+			"handing the secret to another system without passing the values through Terraform. The secret is " +
+			"never stored in state, only the token. A new token is issued when 'triggers' change; destroying the " +
+			"resource leaves the token valid until it is unwrapped or expires.",
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				Description: "The unique identifier for this resource (mount/path).",
+				Computed:    true,
+			},
+			"mount": schema.StringAttribute{
+				Description:   "The mount path of the KV secrets engine (e.g., 'app_demo').",
+				Required:      true,
+				PlanModifiers: replace,
+			},
+			"path": schema.StringAttribute{
+				Description:   "The path within the mount where the secret lives (e.g., 'my-service/test').",
+				Required:      true,
+				PlanModifiers: replace,
+			},
+			"path_normalization": schema.StringAttribute{
+				Description: "How to treat 'mount' and 'path' values with duplicate slashes or '.' and '..' segments: " +
+					"'normalize' collapses and resolves them, 'error' rejects them. A '..' that climbs above the value " +
+					"itself is always rejected. Defaults to 'normalize'.",
+				Optional: true,
+			},
+			"wrap_ttl_seconds": schema.Int64Attribute{
+				Description: fmt.Sprintf("How long the wrapping token stays valid, in seconds. Defaults to %d.", defaultWrapTTL),
+				Optional:    true,
+				Computed:    true,
+				Default:     int64default.StaticInt64(defaultWrapTTL),
+				PlanModifiers: []planmodifier.Int64{
+					int64planmodifier.RequiresReplace(),
+				},
+			},
+			"triggers": schema.MapAttribute{
+				Description: "Arbitrary values that issue a new wrapping token when changed (e.g., a timestamp or ticket ID).",
+				Optional:    true,
+				ElementType: types.StringType,
+				PlanModifiers: []planmodifier.Map{
+					mapplanmodifier.RequiresReplace(),
+				},
+			},
+			"wrapping_token": schema.StringAttribute{
+				Description: "The single-use token that unwraps to the secret (vault unwrap, or sys/wrapping/unwrap).",
+				Computed:    true,
+				Sensitive:   true,
+			},
+			"wrapping_accessor": schema.StringAttribute{
+				Description: "The accessor of the wrapping token, for looking it up or revoking it without the token.",
+				Computed:    true,
+			},
+			"expires_at": schema.StringAttribute{
+				Description: "When the wrapping token expires (RFC 3339).",
+				Computed:    true,
+			},
+		},
+	}
+}
+
+func (r *WrappedSecretResource) ValidateConfig(ctx context.Context, req resource.ValidateConfigRequest, resp *resource.ValidateConfigResponse) {
+	var config WrappedSecretResourceModel
+	resp.Diagnostics.Append(req.Config.Get(ctx, &config)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if !config.WrapTTLSeconds.IsNull() && !config.WrapTTLSeconds.IsUnknown() && config.WrapTTLSeconds.ValueInt64() < 1 {
+		resp.Diagnostics.AddAttributeError(
+			tfpath.Root("wrap_ttl_seconds"),
+			"Invalid Wrap TTL",
+			fmt.Sprintf("Must be at least 1, got %d.", config.WrapTTLSeconds.ValueInt64()),
+		)
+	}
+}
+
+func (r *WrappedSecretResource) Configure(_ context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+
+	client, ok := req.ProviderData.(*VaultClient)
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Resource Configure Type",
+			"Expected *VaultClient, got something else.",
+		)
+		return
+	}
+
+	r.client = client
+}
+
+func (r *WrappedSecretResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	var plan WrappedSecretResourceModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	mount, path, ok := cleanSecretPaths(plan.Mount.ValueString(), plan.Path.ValueString(), plan.PathNormalization.ValueString(), &resp.Diagnostics)
+	if !ok {
+		return
+	}
+
+	r.client.logOperation(ctx, "Wrapping secret from Vault", map[string]interface{}{
+		"mount": mount,
+		"path":  path,
+		"ttl":   plan.WrapTTLSeconds.ValueInt64(),
+	})
+
+	info, err := r.client.wrapSecret(ctx, mount, path, plan.WrapTTLSeconds.ValueInt64())
+	if err != nil {
+		if errors.Is(err, errMountMissing) {
+			resp.Diagnostics.AddError(
+				"Mount Not Found",
+				fmt.Sprintf("Vault has no secrets engine at %q: the mount appears to have been disabled or moved.", mount),
+			)
+			return
+		}
+		resp.Diagnostics.AddError(
+			"Failed to Wrap Secret",
+			fmt.Sprintf("Could not wrap %s/%s: %s", mount, path, err),
+		)
+		return
+	}
+
+	plan.ID = types.StringValue(fmt.Sprintf("%s/%s", mount, path))
+	plan.WrappingToken = types.StringValue(info.Token)
+	plan.WrappingAccessor = types.StringValue(info.Accessor)
+	plan.ExpiresAt = types.StringNull()
+	if created, err := time.Parse(time.RFC3339Nano, info.CreationTime); err == nil {
+		expires := created.Add(time.Duration(info.TTL) * time.Second)
+		plan.ExpiresAt = types.StringValue(expires.UTC().Format(time.RFC3339))
+	}
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &plan)...)
+}
+
+func (r *WrappedSecretResource) Read(_ context.Context, _ resource.ReadRequest, _ *resource.ReadResponse) {
+	// A wrapping token is handed off once; refresh keeps it even after it
+	// was unwrapped or expired, so consuming it does not cause a new one.
+}
+
+func (r *WrappedSecretResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	// Every configurable attribute requires replacement, so there is nothing
+	// to update in place.
+	var plan WrappedSecretResourceModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &plan)...)
+}
+
+func (r *WrappedSecretResource) Delete(_ context.Context, _ resource.DeleteRequest, _ *resource.DeleteResponse) {
+	// The token stays valid until it is unwrapped or its TTL runs out.
+}
@@ -0,0 +1,110 @@
+package provider
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"time"
+)
+
+// cachedToken is the content of a token_cache_file. The role ID is stored
+// only as a hash, to tell apart tokens of different roles.
+type cachedToken struct {
+	Address    string    `json:"address"`
+	RoleIDHash string    `json:"role_id_hash"`
+	Token      string    `json:"token"`
+	ExpiresAt  time.Time `json:"expires_at"`
+}
+
+// tokenCacheMinRemaining is how long a cached token must still be valid to
+// be reused, so it does not expire in the middle of a run.
+const tokenCacheMinRemaining = 5 * time.Minute
+
+// errInsecureTokenCache is returned when a token cache file is readable or
+// writable by users other than its owner.
+var errInsecureTokenCache = errors.New("token cache file permissions are too open")
+
+func roleIDHash(roleID string) string {
+	sum := sha256.Sum256([]byte(roleID))
+	return hex.EncodeToString(sum[:])
+}
+
+// loadCachedToken returns the token cached in file for address and roleID
+// if it is still valid. Vault is asked to look the token up in namespace,
+// so revoked tokens are not reused.
+func loadCachedToken(httpClient *http.Client, file, address, namespace, namespaceMode, roleID, tokenHeaderStyle string) (*cachedToken, error) {
+	info, err := os.Stat(file)
+	if err != nil {
+		return nil, err
+	}
+	if info.Mode().Perm()&0o077 != 0 {
+		return nil, fmt.Errorf("%w: %s has mode %s, expected 0600", errInsecureTokenCache, file, info.Mode().Perm())
+	}
+
+	raw, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+
+	var cached cachedToken
+	if err := json.Unmarshal(raw, &cached); err != nil {
+		return nil, fmt.Errorf("failed to parse token cache: %w", err)
+	}
+
+	if cached.Token == "" || cached.Address != address || cached.RoleIDHash != roleIDHash(roleID) {
+		return nil, fmt.Errorf("token cache is for a different address or role")
+	}
+	if !cached.ExpiresAt.IsZero() && time.Until(cached.ExpiresAt) < tokenCacheMinRemaining {
+		return nil, fmt.Errorf("cached token expires at %s", cached.ExpiresAt.Format(time.RFC3339))
+	}
+
+	lookupURL := fmt.Sprintf("%s/v1/auth/token/lookup-self", namespaceBase(address, namespace, namespaceMode))
+	req, err := http.NewRequest("GET", lookupURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	setTokenHeader(req, tokenHeaderStyle, cached.Token)
+	setNamespaceHeader(req, namespace, namespaceMode)
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check cached token: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("cached token was rejected by vault with status %d", resp.StatusCode)
+	}
+
+	return &cached, nil
+}
+
+// saveCachedToken writes a token to file, readable only by its owner.
+func saveCachedToken(file, address, roleID, token string, expiresAt time.Time) error {
+	raw, err := json.Marshal(cachedToken{
+		Address:    address,
+		RoleIDHash: roleIDHash(roleID),
+		Token:      token,
+		ExpiresAt:  expiresAt,
+	})
+	if err != nil {
+		return err
+	}
+
+	f, err := os.OpenFile(file, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0o600)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	// OpenFile keeps the mode of an existing file, so tighten it explicitly.
+	if err := f.Chmod(0o600); err != nil {
+		return err
+	}
+	_, err = f.Write(raw)
+	return err
+}
@@ -0,0 +1,133 @@
+package provider
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// valueTransform is a named normalization applied to a key's value before it
+// is written. A key's transforms value is a pipeline of names separated by
+// transformSeparator, applied left to right (e.g. "trim|base64encode").
+type valueTransform struct {
+	apply func(string) string
+
+	// invert recovers the configured value from the stored one. Nil when the
+	// transform is not reversible.
+	invert func(string) (string, error)
+}
+
+var valueTransforms = map[string]valueTransform{
+	"trim":      {apply: strings.TrimSpace},
+	"uppercase": {apply: strings.ToUpper},
+	"lowercase": {apply: strings.ToLower},
+	"upper":     {apply: strings.ToUpper},
+	"lower":     {apply: strings.ToLower},
+	"base64encode": {
+		apply: func(v string) string { return base64.StdEncoding.EncodeToString([]byte(v)) },
+		invert: func(v string) (string, error) {
+			decoded, err := base64.StdEncoding.DecodeString(v)
+			return string(decoded), err
+		},
+	},
+}
+
+const transformSeparator = "|"
+
+// parseTransformPipeline returns the transforms named in spec, in the order
+// they are applied. An empty spec is an empty pipeline.
+func parseTransformPipeline(spec string) ([]valueTransform, error) {
+	if spec == "" {
+		return nil, nil
+	}
+	var pipeline []valueTransform
+	for _, name := range strings.Split(spec, transformSeparator) {
+		t, ok := valueTransforms[strings.TrimSpace(name)]
+		if !ok {
+			return nil, fmt.Errorf("%q is not a transform; use one of %s, separated by %q to apply several in order",
+				strings.TrimSpace(name), strings.Join(transformNames(), ", "), transformSeparator)
+		}
+		pipeline = append(pipeline, t)
+	}
+	return pipeline, nil
+}
+
+// applyPipeline runs value through every transform of pipeline in order.
+func applyPipeline(pipeline []valueTransform, value string) string {
+	for _, t := range pipeline {
+		value = t.apply(value)
+	}
+	return value
+}
+
+// invertPipeline undoes pipeline, last transform first. It fails when any
+// transform is not reversible.
+func invertPipeline(pipeline []valueTransform, value string) (string, error) {
+	for i := len(pipeline) - 1; i >= 0; i-- {
+		if pipeline[i].invert == nil {
+			return "", fmt.Errorf("transform is not reversible")
+		}
+		var err error
+		if value, err = pipeline[i].invert(value); err != nil {
+			return "", err
+		}
+	}
+	return value, nil
+}
+
+// transformNames returns the supported transform names, sorted.
+func transformNames() []string {
+	names := make([]string, 0, len(valueTransforms))
+	for name := range valueTransforms {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// keyTransforms reads the key name to transform name map of a resource.
+func keyTransforms(ctx context.Context, transforms types.Map) (map[string]string, diag.Diagnostics) {
+	result := make(map[string]string)
+	if transforms.IsNull() || transforms.IsUnknown() {
+		return result, nil
+	}
+	diags := transforms.ElementsAs(ctx, &result, false)
+	return result, diags
+}
+
+// transformKeys returns a copy of keys with each key's transform pipeline
+// applied. Pipelines are validated with the configuration, so an invalid
+// one leaves the value unchanged.
+func transformKeys(keys, transforms map[string]string) map[string]string {
+	result := make(map[string]string, len(keys))
+	for key, value := range keys {
+		if pipeline, err := parseTransformPipeline(transforms[key]); err == nil {
+			value = applyPipeline(pipeline, value)
+		}
+		result[key] = value
+	}
+	return result
+}
+
+// untransformedValue returns the value to record in state for a key whose
+// stored value is live. The configured value is kept while it still
+// transforms to live, so normalization never shows up as drift. Otherwise a
+// fully reversible pipeline is undone and any other reports live as is.
+func untransformedValue(transform, configured, live string) string {
+	pipeline, err := parseTransformPipeline(transform)
+	if err != nil || len(pipeline) == 0 {
+		return live
+	}
+	if applyPipeline(pipeline, configured) == live {
+		return configured
+	}
+	if original, err := invertPipeline(pipeline, live); err == nil {
+		return original
+	}
+	return live
+}
@@ -0,0 +1,68 @@
+package provider
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os/exec"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	tfpath "github.com/hashicorp/terraform-plugin-framework/path"
+)
+
+// runValueCommand pipes value through the program argv names: the value is
+// written to its stdin and its stdout, unchanged, is the result. stdout is
+// never included in errors, as it may hold the value.
+func runValueCommand(ctx context.Context, argv []string, value string) (string, error) {
+	cmd := exec.CommandContext(ctx, argv[0], argv[1:]...)
+	cmd.Stdin = strings.NewReader(value)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if detail := strings.TrimSpace(stderr.String()); detail != "" {
+			return "", fmt.Errorf("%s: %w: %s", argv[0], err, detail)
+		}
+		return "", fmt.Errorf("%s: %w", argv[0], err)
+	}
+	return stdout.String(), nil
+}
+
+// commandKeys returns a copy of keys with the values of the named keys run
+// through argv. Other keys are copied as is.
+func commandKeys(ctx context.Context, argv []string, keys map[string]string, names map[string]string) (map[string]string, error) {
+	result := make(map[string]string, len(keys))
+	for key, value := range keys {
+		if _, ok := names[key]; ok {
+			converted, err := runValueCommand(ctx, argv, value)
+			if err != nil {
+				return nil, fmt.Errorf("key %q: %w", key, err)
+			}
+			value = converted
+		}
+		result[key] = value
+	}
+	return result, nil
+}
+
+// valueCommands returns the value_command and value_read_command of model,
+// or nils when they are not set. Running them requires the provider's
+// allow_value_commands.
+func (r *KvKeysResource) valueCommands(ctx context.Context, model KvKeysResourceModel, diags *diag.Diagnostics) (write, read []string) {
+	if model.ValueCommand.IsNull() {
+		return nil, nil
+	}
+	if !r.client.AllowValueCommands {
+		diags.AddAttributeError(
+			tfpath.Root("value_command"),
+			"Value Commands Not Allowed",
+			"'value_command' runs a local program on every managed value. Set allow_value_commands = true on the "+
+				"provider to accept that risk.",
+		)
+		return nil, nil
+	}
+	diags.Append(model.ValueCommand.ElementsAs(ctx, &write, false)...)
+	diags.Append(model.ValueReadCommand.ElementsAs(ctx, &read, false)...)
+	return write, read
+}
@@ -0,0 +1,76 @@
+package provider
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+)
+
+const (
+	valueTypeString  = "string"
+	valueTypeNumber  = "number"
+	valueTypeBoolean = "boolean"
+)
+
+// jsonNumberPattern is the JSON number grammar (RFC 8259). Anything else,
+// such as "08080", "+1", "0x1F" or "1_000", is rejected.
+var jsonNumberPattern = regexp.MustCompile(`^-?(0|[1-9][0-9]*)(\.[0-9]+)?([eE][+-]?[0-9]+)?$`)
+
+// coerceValue converts value to the JSON type kind names. Only exact JSON
+// literals are accepted: "true" and "false" for booleans and the JSON number
+// grammar for numbers. Errors never include the value.
+func coerceValue(kind, value string) (interface{}, error) {
+	switch kind {
+	case valueTypeNumber:
+		if !jsonNumberPattern.MatchString(value) {
+			return nil, fmt.Errorf("the value is not a JSON number")
+		}
+		return json.Number(value), nil
+	case valueTypeBoolean:
+		switch value {
+		case "true":
+			return true, nil
+		case "false":
+			return false, nil
+		}
+		return nil, fmt.Errorf("the value is not \"true\" or \"false\"")
+	default:
+		return value, nil
+	}
+}
+
+// typedData returns data with the values of the keys in types converted to
+// their JSON type.
+func typedData(data, types map[string]string) (map[string]interface{}, error) {
+	typed := make(map[string]interface{}, len(data))
+	for key, value := range data {
+		converted, err := coerceValue(types[key], value)
+		if err != nil {
+			return nil, fmt.Errorf("key %q: %w", key, err)
+		}
+		typed[key] = converted
+	}
+	return typed, nil
+}
+
+// rawValueString renders a decoded secret value as the string the provider
+// manages. Numbers keep their plain decimal form instead of Go's exponent
+// notation for large values.
+func rawValueString(v interface{}) string {
+	if f, ok := v.(float64); ok {
+		return strconv.FormatFloat(f, 'f', -1, 64)
+	}
+	return fmt.Sprintf("%v", v)
+}
+
+// sameTypedValue reports whether a configured value and the live one read
+// back denote the same value of type kind, e.g. "1.50" and "1.5".
+func sameTypedValue(kind, configured, live string) bool {
+	if kind != valueTypeNumber || !jsonNumberPattern.MatchString(configured) {
+		return configured == live
+	}
+	a, errA := strconv.ParseFloat(configured, 64)
+	b, errB := strconv.ParseFloat(live, 64)
+	return errA == nil && errB == nil && a == b
+}
@@ -0,0 +1,110 @@
+package provider
+
+import (
+	"context"
+	"reflect"
+	"testing"
+)
+
+func TestCoerceValue(t *testing.T) {
+	tests := []struct {
+		kind, value string
+		want        interface{}
+		wantErr     bool
+	}{
+		{valueTypeString, "08080", "08080", false},
+		{valueTypeNumber, "8080", "8080", false},
+		{valueTypeNumber, "1.50", "1.50", false},
+		{valueTypeNumber, "-2e10", "-2e10", false},
+		{valueTypeNumber, "08080", nil, true},
+		{valueTypeNumber, "0x1F", nil, true},
+		{valueTypeBoolean, "true", true, false},
+		{valueTypeBoolean, "false", false, false},
+		{valueTypeBoolean, "yes", nil, true},
+	}
+	for _, tt := range tests {
+		got, err := coerceValue(tt.kind, tt.value)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("coerceValue(%q, %q) error = %v, wantErr %v", tt.kind, tt.value, err, tt.wantErr)
+			continue
+		}
+		if tt.wantErr {
+			continue
+		}
+		if n, ok := got.(interface{ String() string }); ok {
+			got = n.String()
+		}
+		if got != tt.want {
+			t.Errorf("coerceValue(%q, %q) = %v, want %v", tt.kind, tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestSameTypedValue(t *testing.T) {
+	tests := []struct {
+		kind, configured, live string
+		want                   bool
+	}{
+		{valueTypeNumber, "1.50", "1.5", true},
+		{valueTypeNumber, "100", "100", true},
+		{valueTypeNumber, "1e3", "1000", true},
+		{valueTypeNumber, "1.50", "1.51", false},
+		{valueTypeString, "1.50", "1.5", false},
+		{"", "1.50", "1.5", false},
+		{valueTypeBoolean, "true", "true", true},
+	}
+	for _, tt := range tests {
+		if got := sameTypedValue(tt.kind, tt.configured, tt.live); got != tt.want {
+			t.Errorf("sameTypedValue(%q, %q, %q) = %v, want %v", tt.kind, tt.configured, tt.live, got, tt.want)
+		}
+	}
+}
+
+func TestDriftedKeysTypedValues(t *testing.T) {
+	live := map[string]string{"RATIO": "1.5", "NAME": "1.5", "PORT": "8080"}
+	state := map[string]string{"RATIO": "1.50", "NAME": "1.50", "PORT": "8081"}
+	types := map[string]string{"RATIO": valueTypeNumber, "PORT": valueTypeNumber}
+
+	got := driftedKeys(live, state, nil, types)
+	if want := []string{"NAME", "PORT"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("driftedKeys = %v, want %v", got, want)
+	}
+}
+
+func TestWriteValueTypesRoundTrip(t *testing.T) {
+	store := newKVStore(t, "app")
+	client := newTestClient(t, store.ServeHTTP).withValueTypes(map[string]string{
+		"RATIO":   valueTypeNumber,
+		"ENABLED": valueTypeBoolean,
+	})
+	client.VerifyWrite = true
+	ctx := context.Background()
+
+	data := map[string]string{"RATIO": "1.50", "ENABLED": "true", "NAME": "svc"}
+	if _, err := client.writeSecret(ctx, "app", "svc", data); err != nil {
+		t.Fatalf("write with trailing zeros failed verification: %s", err)
+	}
+
+	stored := store.latest("svc")
+	if _, ok := stored["RATIO"].(float64); !ok {
+		t.Errorf("RATIO stored as %T, want a JSON number", stored["RATIO"])
+	}
+	if stored["ENABLED"] != true {
+		t.Errorf("ENABLED stored as %#v, want true", stored["ENABLED"])
+	}
+	if stored["NAME"] != "svc" {
+		t.Errorf("NAME stored as %#v, want \"svc\"", stored["NAME"])
+	}
+
+	read, err := client.readSecret(ctx, "app", "svc")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]string{"RATIO": "1.5", "ENABLED": "true", "NAME": "svc"}
+	if !reflect.DeepEqual(read, want) {
+		t.Errorf("read back %v, want %v", read, want)
+	}
+	if drifted := driftedKeys(read, data, nil, client.ValueTypes); len(drifted) > 0 {
+		t.Errorf("round trip reported drift for %v", drifted)
+	}
+}